@@ -0,0 +1,57 @@
+package main
+
+import (
+	"postService/internal/config"
+	"postService/internal/database"
+	"postService/internal/logging"
+	"postService/internal/metrics"
+	"postService/internal/repository"
+	"postService/internal/service"
+	"postService/internal/tracing"
+)
+
+// app wires the database, repositories and services the subcommands share
+type app struct {
+	db       *database.Database
+	metrics  *metrics.Metrics
+	posts    service.PostService
+	comments service.CommentService
+}
+
+func newApp(cfg *config.Config) (*app, error) {
+	if err := logging.SetLevel(cfg.Log.Level); err != nil {
+		return nil, err
+	}
+
+	db, err := database.NewDatabase(cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB.DB()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	m := metrics.New(sqlDB)
+
+	if err := db.DB.Use(tracing.NewGormPlugin()); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	postRepo := repository.TracePostRepository(repository.NewPostgresPostRepository(db.DB))
+	commentRepo := repository.TraceCommentRepository(repository.NewPostgresCommentRepository(db.DB))
+
+	posts := service.TracePostService(service.NewPostService(postRepo))
+	return &app{
+		db:       db,
+		metrics:  m,
+		posts:    service.InstrumentPostService(posts, m),
+		comments: service.TraceCommentService(service.NewCommentService(commentRepo, postRepo)),
+	}, nil
+}
+
+func (a *app) Close() error {
+	return a.db.Close()
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"postService/internal/config"
+	"postService/internal/handlers"
+	"postService/internal/logging"
+	"postService/internal/middleware"
+	"postService/internal/service"
+	"postService/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// The phases serve goes through before it accepts requests, in order
+const (
+	phaseConfig     = "config"
+	phaseDatabase   = "database"
+	phaseMigrations = "migrations"
+	phaseWarmup     = "warmup"
+)
+
+// The backoff between attempts to connect to the database doubles up to
+// maxConnectBackoff
+const (
+	minConnectBackoff = time.Second
+	maxConnectBackoff = 10 * time.Second
+)
+
+// switchHandler serves with the router stored last, so serve can listen with
+// the boot router and swap in the full one once the service has booted
+type switchHandler struct {
+	current atomic.Pointer[gin.Engine]
+}
+
+func (h *switchHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.current.Load().ServeHTTP(w, r)
+}
+
+// newBootRouter routes the probes while the service boots. Liveness passes,
+// readiness and the startup probe report the pending phase, and every other
+// request is answered 503 with Retry-After.
+func newBootRouter(healthHandler *handlers.HealthHandler) *gin.Engine {
+	router := gin.New()
+	router.Use(tracing.Middleware(), middleware.RequestID(), logging.AccessLog(), middleware.Recovery())
+	router.NoRoute(middleware.Starting)
+
+	for _, group := range []*gin.RouterGroup{&router.RouterGroup, router.Group("/api/v1")} {
+		group.GET("/health/live", healthHandler.GetLiveness)
+		group.GET("/health/ready", healthHandler.GetReadiness)
+		group.GET("/health/startup", healthHandler.GetStartup)
+	}
+
+	return router
+}
+
+// connect wires the app, retrying with backoff while the database refuses
+// connections, so the service may start before the database does. It gives
+// up once cfg.Database.ConnectTimeout has passed or ctx is cancelled.
+func connect(ctx context.Context, cfg *config.Config, startup *service.StartupTracker) (*app, error) {
+	deadline := time.Now().Add(cfg.Database.ConnectTimeout)
+	backoff := minConnectBackoff
+
+	for {
+		a, err := newApp(cfg)
+		startup.Attempt(phaseDatabase, err)
+		if err == nil {
+			return a, nil
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, fmt.Errorf("gave up connecting after %s: %w", cfg.Database.ConnectTimeout, err)
+		}
+
+		wait := min(backoff, remaining)
+		slog.Warn("Failed to connect to database, retrying", "error", err, "retry_in", wait.String())
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+		backoff = min(2*backoff, maxConnectBackoff)
+	}
+}
+
+// abortBoot stops the server after a boot phase failed with err. A phase
+// interrupted by a signal is not a failure, and one interrupted because the
+// listener failed reports the listener's error instead.
+func abortBoot(signalled context.Context, server *http.Server, errs <-chan error, err error) error {
+	select {
+	case listenErr := <-errs:
+		return listenErr
+	default:
+	}
+	server.Close()
+
+	if signalled.Err() != nil {
+		slog.Info("Interrupted while starting")
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"postService/internal/config"
+	"postService/internal/handlers"
+	"postService/internal/metrics"
+	"postService/internal/repository"
+	"postService/internal/service"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// newTestApp wires the services against in-memory repositories
+func newTestApp() *app {
+	posts := repository.NewInMemoryPostRepository()
+	return &app{
+		metrics:  metrics.New(nil),
+		posts:    service.NewPostService(posts),
+		comments: service.NewCommentService(repository.NewInMemoryCommentRepository(), posts),
+	}
+}
+
+func TestSwitchHandlerServesBootRouterUntilStarted(t *testing.T) {
+	cfg := config.Default()
+	startup := service.NewStartupTracker(phaseConfig, phaseDatabase, phaseMigrations, phaseWarmup)
+	healthService := service.NewHealthService(service.NewHealthRegistry(), startup, "test", cfg.Health)
+	healthHandler := handlers.NewHealthHandler(healthService)
+
+	handler := &switchHandler{}
+	handler.current.Store(newBootRouter(healthHandler))
+
+	status := func(path string) int {
+		t.Helper()
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w.Code
+	}
+	assertStatuses := func(when string, want map[string]int) {
+		t.Helper()
+		for path, code := range want {
+			if got := status(path); got != code {
+				t.Errorf("%s: GET %s = %d, want %d", when, path, got, code)
+			}
+		}
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/posts", nil))
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") == "" {
+		t.Errorf("booting: GET /api/v1/posts = %d with Retry-After %q, want 503 with a Retry-After",
+			w.Code, w.Header().Get("Retry-After"))
+	}
+	assertStatuses("booting", map[string]int{
+		"/health/live":           http.StatusOK,
+		"/health/ready":          http.StatusServiceUnavailable,
+		"/api/v1/health/startup": http.StatusServiceUnavailable,
+		"/metrics":               http.StatusServiceUnavailable,
+	})
+
+	for _, phase := range []string{phaseConfig, phaseDatabase, phaseMigrations} {
+		startup.Run(phase, func() error { return nil })
+	}
+	startup.Run(phaseWarmup, func() error {
+		handler.current.Store(newRouter(cfg, newTestApp(), healthHandler))
+		// The real routes answer before the probes pass
+		assertStatuses("warming up", map[string]int{
+			"/api/v1/posts":   http.StatusOK,
+			"/health/ready":   http.StatusServiceUnavailable,
+			"/health/startup": http.StatusServiceUnavailable,
+		})
+		return nil
+	})
+
+	assertStatuses("started", map[string]int{
+		"/api/v1/posts":   http.StatusOK,
+		"/health/live":    http.StatusOK,
+		"/health/ready":   http.StatusOK,
+		"/health/startup": http.StatusOK,
+		"/metrics":        http.StatusOK,
+	})
+}
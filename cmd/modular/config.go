@@ -0,0 +1,35 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"postService/internal/config"
+)
+
+// runConfig implements the config subcommand
+func runConfig(args []string) error {
+	if len(args) == 0 || args[0] != "print" {
+		return fmt.Errorf("usage: modular config print [flags]")
+	}
+
+	flags := flag.NewFlagSet("config print", flag.ExitOnError)
+	loader := config.NewLoader(flags)
+	flags.Parse(args[1:])
+
+	cfg, err := loader.Load()
+	if err != nil {
+		return err
+	}
+
+	// YAML, so the output can be used as a config file once secrets are filled in
+	encoder := yaml.NewEncoder(os.Stdout)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(cfg.Redacted()); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"postService/internal/config"
+	"postService/internal/models"
+)
+
+// runExport implements the export subcommand. Posts in the trash are left out.
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	loader := config.NewLoader(flags)
+	output := flags.String("output", "-", "file to write to, or - for stdout")
+	flags.Parse(args)
+
+	cfg, err := loader.Load()
+	if err != nil {
+		return err
+	}
+
+	a, err := newApp(cfg)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	var w io.Writer = os.Stdout
+	if *output != "-" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	buffered := bufio.NewWriter(w)
+	count, err := exportPosts(context.Background(), a, buffered)
+	if err != nil {
+		return err
+	}
+	if err := buffered.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Exported %d posts\n", count)
+	return nil
+}
+
+// exportPosts writes every live post, oldest first, as one JSON object per line
+func exportPosts(ctx context.Context, a *app, w io.Writer) (int, error) {
+	encoder := json.NewEncoder(w)
+	query := models.PostQuery{
+		Sort: []models.SortField{{Field: "created_at"}},
+		Page: models.PageRequest{Limit: models.MaxPageLimit},
+	}
+
+	count := 0
+	for {
+		page, err := a.posts.ListPosts(ctx, query)
+		if err != nil {
+			return count, err
+		}
+		for _, post := range page.Data {
+			if err := encoder.Encode(post); err != nil {
+				return count, err
+			}
+			count++
+		}
+		if !page.Page.HasMore {
+			return count, nil
+		}
+
+		if query.Page.Cursor, err = models.DecodeCursor(page.Page.NextCursor); err != nil {
+			return count, err
+		}
+	}
+}
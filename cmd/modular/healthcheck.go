@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"time"
+
+	"postService/internal/config"
+)
+
+// runHealthcheck implements the healthcheck subcommand. It asks a running
+// server whether it is ready and fails unless it answers 200, which makes it
+// usable as a Docker HEALTHCHECK in images without curl.
+func runHealthcheck(args []string) error {
+	flags := flag.NewFlagSet("healthcheck", flag.ExitOnError)
+	loader := config.NewLoader(flags)
+	url := flags.String("url", "", "readiness endpoint to check (default http://localhost:<server.port>/health/ready)")
+	timeout := flags.Duration("timeout", 3*time.Second, "how long to wait for an answer")
+	flags.Parse(args)
+
+	if *url == "" {
+		cfg, err := loader.Load()
+		if err != nil {
+			return err
+		}
+		*url = "http://localhost:" + cfg.Server.Port + "/health/ready"
+	}
+
+	client := &http.Client{Timeout: *timeout}
+	resp, err := client.Get(*url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s answered %s", *url, resp.Status)
+	}
+	return nil
+}
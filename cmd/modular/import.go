@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"postService/internal/config"
+	"postService/internal/models"
+	"postService/internal/service"
+)
+
+// runImport implements the import subcommand. Posts whose ID already exists
+// are skipped, so an export can be imported again after a partial failure.
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	loader := config.NewLoader(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: modular import [file]\n\nReads posts written by export from file, or stdin when it is - or omitted.")
+	}
+	flags.Parse(args)
+
+	var r io.Reader = os.Stdin
+	if name := flags.Arg(0); name != "" && name != "-" {
+		file, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	cfg, err := loader.Load()
+	if err != nil {
+		return err
+	}
+
+	a, err := newApp(cfg)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	ctx := context.Background()
+	decoder := json.NewDecoder(r)
+	var created, skipped, failed int
+	for line := 1; ; line++ {
+		var post models.Post
+		if err := decoder.Decode(&post); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("post %d: %w", line, err)
+		}
+
+		switch err := a.posts.ImportPost(ctx, &post); {
+		case err == nil:
+			created++
+		case errors.Is(err, service.ErrConflict):
+			skipped++
+		default:
+			slog.Warn("Failed to import post", "line", line, "id", post.ID, "error", err)
+			failed++
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Imported %d posts, skipped %d existing, %d failed\n", created, skipped, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d posts failed to import", failed)
+	}
+	return nil
+}
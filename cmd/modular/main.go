@@ -1,82 +1,76 @@
 package main
 
 import (
-	"log"
+	"fmt"
+	"log/slog"
 	"os"
+	"sort"
 
-	"postService/internal/database"
-	"postService/internal/handlers"
-	"postService/internal/repository"
-	"postService/internal/service"
+	"postService/internal/logging"
+)
 
-	"github.com/gin-gonic/gin"
+// version is reported by the health endpoints and in traces
+const version = "1.0.0"
 
-	swaggerFiles "github.com/swaggo/files"
-	ginSwagger "github.com/swaggo/gin-swagger"
-	_ "postService/docs"
-)
+// command is a subcommand of the modular binary
+type command struct {
+	summary string
+	run     func(args []string) error
+}
+
+var commands = map[string]command{
+	"serve":       {"Run the HTTP server (the default)", runServe},
+	"migrate":     {"Apply, revert or list database migrations", runMigrate},
+	"seed":        {"Create sample posts", runSeed},
+	"export":      {"Write every post as JSON lines", runExport},
+	"import":      {"Create posts from JSON lines written by export", runImport},
+	"healthcheck": {"Exit non-zero unless the server is ready", runHealthcheck},
+	"config":      {"Print the effective configuration", runConfig},
+}
 
 // @title Post Service API
 // @version 1.0
 // @description A simple post service with CRUD operations
 // @host localhost:8080
 // @BasePath /api/v1
+// @securityDefinitions.apikey AdminToken
+// @in header
+// @name Authorization
+// @description Admin bearer token, sent as "Bearer <ADMIN_TOKEN>"
 func main() {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
+	// Logs go to stderr, keeping stdout for command output such as exports
+	slog.SetDefault(logging.New(os.Stderr))
 
-	// Initialize database
-	dbConfig := database.NewConfig()
-	db, err := database.NewDatabase(dbConfig)
-	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
 	}
-	defer db.Close()
 
-	// Run migrations
-	if err := db.Migrate(); err != nil {
-		log.Fatal("Failed to run migrations:", err)
+	if name == "help" || name == "-h" || name == "--help" {
+		usage()
+		return
 	}
 
-	// Initialize repository with PostgreSQL
-	repo := repository.NewPostgresPostRepository(db.DB)
-	postService := service.NewPostService(repo)
-	postHandler := handlers.NewPostHandler(postService)
-
-	// Initialize health service and handler
-	healthService := service.NewHealthService(db, "1.0.0")
-	healthHandler := handlers.NewHealthHandler(healthService)
-
-	router := gin.Default()
-
-	// API endpoints
-	v1 := router.Group("/api/v1")
-	{
-		// Post endpoints
-		v1.POST("/posts", postHandler.CreatePost)
-		v1.GET("/posts", postHandler.GetAllPosts)
-		v1.GET("/posts/:id", postHandler.GetPost)
-		v1.PUT("/posts/:id", postHandler.UpdatePost)
-		v1.DELETE("/posts/:id", postHandler.DeletePost)
-		
-		// Health endpoints
-		v1.GET("/health", healthHandler.GetHealth)           // GET /api/v1/health
-		v1.GET("/health/live", healthHandler.GetLiveness)    // GET /api/v1/health/live
-		v1.GET("/health/ready", healthHandler.GetReadiness)  // GET /api/v1/health/ready
-		v1.GET("/health/ping", healthHandler.GetHealthSimple) // GET /api/v1/health/ping
-		v1.GET("/health/component/:component", healthHandler.GetComponentHealth) // GET /api/v1/health/component/{name}
+	cmd, ok := commands[name]
+	if !ok {
+		usage()
+		os.Exit(2)
 	}
+	if err := cmd.run(args); err != nil {
+		slog.Error("Command failed", "command", name, "error", err)
+		os.Exit(1)
+	}
+}
 
-	// Keep infrastructure health endpoints for Kubernetes probes (no versioning)
-	router.GET("/health/live", healthHandler.GetLiveness)
-	router.GET("/health/ready", healthHandler.GetReadiness)
-
-	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+func usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
 
-	log.Printf("Server starting on port %s", port)
-	if err := router.Run(":" + port); err != nil {
-		log.Fatal("Failed to start server:", err)
+	fmt.Fprintln(os.Stderr, "Usage: modular <command> [arguments]\n\nCommands:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", name, commands[name].summary)
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"postService/internal/config"
+	"postService/internal/database"
+	"postService/internal/logging"
+)
+
+const migrateUsage = `Usage: modular migrate [--dry-run] <command>
+
+Commands:
+  status    List migrations and whether they are applied
+  up        Apply every pending migration
+  down      Revert the most recently applied migration
+  to N      Apply or revert migrations until N is the latest applied (0 reverts all)
+`
+
+// runMigrate implements the migrate subcommand
+func runMigrate(args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	loader := config.NewLoader(flags)
+	dryRun := flags.Bool("dry-run", false, "print the migrations that would run without running them")
+	flags.Usage = func() { fmt.Fprint(flags.Output(), migrateUsage) }
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	command, version := flags.Arg(0), 0
+	switch command {
+	case "status", "up", "down":
+	case "to":
+		var err error
+		if version, err = strconv.Atoi(flags.Arg(1)); flags.NArg() != 2 || err != nil || version < 0 {
+			return fmt.Errorf("migrate to needs a version number")
+		}
+	default:
+		return fmt.Errorf("unknown migrate command %q", command)
+	}
+
+	cfg, err := loader.Load()
+	if err != nil {
+		return err
+	}
+	if err := logging.SetLevel(cfg.Log.Level); err != nil {
+		return err
+	}
+
+	db, err := database.NewDatabase(cfg.Database)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := db.Migrator()
+	if err != nil {
+		return err
+	}
+	migrator.DryRun = *dryRun
+
+	ctx := context.Background()
+	var steps []database.MigrationStep
+	switch command {
+	case "status":
+		return printMigrationStatus(ctx, migrator)
+	case "up":
+		steps, err = migrator.Up(ctx)
+	case "down":
+		steps, err = migrator.Down(ctx)
+	case "to":
+		steps, err = migrator.To(ctx, version)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(steps) == 0 {
+		fmt.Println("Nothing to migrate")
+	}
+	if *dryRun {
+		for _, step := range steps {
+			fmt.Printf("-- %s\n%s\n", step, step.SQL())
+		}
+	}
+	return nil
+}
+
+func printMigrationStatus(ctx context.Context, migrator *database.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+	}
+	return w.Flush()
+}
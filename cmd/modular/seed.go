@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+
+	"postService/internal/config"
+	"postService/internal/models"
+)
+
+var (
+	seedAuthors = []string{"Ada Lovelace", "Grace Hopper", "Alan Turing", "Barbara Liskov", "Ken Thompson"}
+	seedWords   = []string{"modern", "practical", "scalable", "simple", "reliable", "hidden", "gentle", "robust"}
+	seedTopics  = []string{"Go", "Kubernetes", "PostgreSQL", "Testing", "Observability", "APIs", "Caching"}
+	seedTags    = []string{"go", "kubernetes", "postgres", "testing", "devops", "api", "performance"}
+)
+
+// runSeed implements the seed subcommand, which fills the database with
+// sample posts for local development
+func runSeed(args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	loader := config.NewLoader(flags)
+	count := flags.Int("count", 10, "number of posts to create")
+	flags.Parse(args)
+
+	if *count <= 0 {
+		return fmt.Errorf("--count must be positive")
+	}
+
+	cfg, err := loader.Load()
+	if err != nil {
+		return err
+	}
+
+	a, err := newApp(cfg)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	ctx := context.Background()
+	for i := 0; i < *count; i++ {
+		if _, err := a.posts.CreatePost(ctx, seedPost()); err != nil {
+			return fmt.Errorf("created %d of %d posts: %w", i, *count, err)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Created %d posts\n", *count)
+	return nil
+}
+
+// seedPost returns a random post; roughly one in five is left as a draft
+func seedPost() models.CreatePostRequest {
+	topic := seedTopics[rand.Intn(len(seedTopics))]
+	word := seedWords[rand.Intn(len(seedWords))]
+
+	req := models.CreatePostRequest{
+		Title:   fmt.Sprintf("A %s guide to %s", word, topic),
+		Content: strings.Repeat(fmt.Sprintf("Notes on %s, kept %s. ", topic, word), 1+rand.Intn(5)),
+		Author:  seedAuthors[rand.Intn(len(seedAuthors))],
+		Status:  models.PostStatusPublished,
+	}
+	if rand.Intn(5) == 0 {
+		req.Status = models.PostStatusDraft
+	}
+	for _, i := range rand.Perm(len(seedTags))[:rand.Intn(4)] {
+		req.Tags = append(req.Tags, seedTags[i])
+	}
+	return req
+}
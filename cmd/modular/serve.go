@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"postService/internal/config"
+	"postService/internal/handlers"
+	"postService/internal/logging"
+	"postService/internal/middleware"
+	"postService/internal/repository"
+	"postService/internal/service"
+	"postService/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+	_ "postService/docs"
+)
+
+// runServe implements the serve subcommand, which is also the default. The
+// server starts listening before the service has booted and answers the
+// probes while it does; the full router is swapped in once every boot phase
+// has completed.
+func runServe(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	loader := config.NewLoader(flags)
+	flags.Parse(args)
+
+	startup := service.NewStartupTracker(phaseConfig, phaseDatabase, phaseMigrations, phaseWarmup)
+	var cfg *config.Config
+	err := startup.Run(phaseConfig, func() (err error) {
+		cfg, err = loader.Load()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, version)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("Failed to flush traces", "error", err)
+		}
+	}()
+
+	signalled, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Checkers are registered once the database is up; until then readiness
+	// fails on the pending boot phase without checking anything
+	healthChecks := service.NewHealthRegistry()
+	healthService := service.NewHealthService(healthChecks, startup, version, cfg.Health)
+	healthHandler := handlers.NewHealthHandler(healthService)
+
+	handler := &switchHandler{}
+	handler.current.Store(newBootRouter(healthHandler))
+	server := &http.Server{
+		Addr:              ":" + cfg.Server.Port,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// ctx is also cancelled when the listener fails, which aborts booting
+	ctx, cancel := context.WithCancel(signalled)
+	defer cancel()
+	errs := make(chan error, 1)
+	go func() {
+		slog.Info("Server starting", "port", cfg.Server.Port)
+		errs <- server.ListenAndServe()
+		cancel()
+	}()
+
+	var a *app
+	err = startup.Run(phaseDatabase, func() (err error) {
+		a, err = connect(ctx, cfg, startup)
+		return err
+	})
+	if err != nil {
+		return abortBoot(signalled, server, errs, err)
+	}
+	// Deferred calls run in reverse, so the pool closes after the scheduler
+	// and the health probe stop, and spans are flushed last
+	defer a.Close()
+
+	err = startup.Run(phaseMigrations, func() error {
+		if err := a.db.Migrate(ctx); err != nil {
+			return err
+		}
+		// Give posts that predate slugs one
+		return repository.BackfillSlugs(ctx, a.db.DB)
+	})
+	if err != nil {
+		return abortBoot(signalled, server, errs, err)
+	}
+
+	// Promote scheduled posts in the background
+	scheduler := service.NewPublishScheduler(a.posts, cfg.Scheduler.Interval)
+	defer healthService.Stop()
+	defer scheduler.Stop()
+
+	// There is no cache to fill; warming up is checking every component once,
+	// which also opens the first connections of the pool
+	err = startup.Run(phaseWarmup, func() error {
+		healthChecks.Register(
+			service.NewDatabaseChecker(a.db, cfg.Health),
+			service.NewMemoryChecker(cfg.Health),
+			service.NewGoroutineChecker(cfg.Health),
+		)
+		healthService.Start()
+		scheduler.Start()
+		// Swap in the real routes before the last phase completes, so no
+		// probe passes while the boot router still answers
+		handler.current.Store(newRouter(cfg, a, healthHandler))
+		return nil
+	})
+	if err != nil {
+		return abortBoot(signalled, server, errs, err)
+	}
+
+	slog.Info("Server started")
+	return serveUntilSignalled(signalled, server, errs, healthService, cfg.Server)
+}
+
+// newRouter routes every endpoint of the booted service
+func newRouter(cfg *config.Config, a *app, healthHandler *handlers.HealthHandler) *gin.Engine {
+	postHandler := handlers.NewPostHandler(a.posts)
+	commentHandler := handlers.NewCommentHandler(a.comments)
+	configHandler := handlers.NewConfigHandler(cfg)
+	logLevelHandler := handlers.NewLogLevelHandler()
+
+	router := gin.New()
+	router.Use(a.metrics.Middleware(), tracing.Middleware(), middleware.RequestID(), logging.AccessLog(), middleware.Recovery(), middleware.ErrorHandler(), middleware.Timeout(cfg.Server.RequestTimeout))
+	router.NoRoute(middleware.NoRoute)
+
+	// API endpoints
+	v1 := router.Group("/api/v1")
+	{
+		// Post endpoints
+		v1.POST("/posts", postHandler.CreatePost)
+		v1.GET("/posts", postHandler.GetAllPosts)
+		v1.GET("/posts/search", postHandler.SearchPosts)
+		v1.GET("/posts/trash", postHandler.GetTrash)
+		v1.GET("/posts/by-slug/:slug", postHandler.GetPostBySlug)
+		v1.GET("/posts/:id", postHandler.GetPost)
+		v1.PUT("/posts/:id", postHandler.UpdatePost)
+		v1.DELETE("/posts/:id", postHandler.DeletePost)
+		v1.POST("/posts/:id/restore", postHandler.RestorePost)
+		v1.POST("/posts/:id/publish", postHandler.PublishPost)
+		v1.POST("/posts/:id/unpublish", postHandler.UnpublishPost)
+		v1.POST("/posts/:id/archive", postHandler.ArchivePost)
+		v1.GET("/posts/:id/revisions", postHandler.ListRevisions)
+		v1.GET("/posts/:id/revisions/diff", postHandler.DiffRevisions)
+		v1.GET("/posts/:id/revisions/:rev", postHandler.GetRevision)
+		v1.POST("/posts/:id/revisions/:rev/restore", postHandler.RestoreRevision)
+		v1.GET("/tags", postHandler.ListTags)
+
+		// Comment endpoints
+		v1.GET("/posts/:id/comments", commentHandler.ListComments)
+		v1.POST("/posts/:id/comments", commentHandler.CreateComment)
+		v1.GET("/posts/:id/comments/:comment_id", commentHandler.GetComment)
+		v1.PUT("/posts/:id/comments/:comment_id", commentHandler.UpdateComment)
+		v1.DELETE("/posts/:id/comments/:comment_id", commentHandler.DeleteComment)
+
+		// Health endpoints
+		v1.GET("/health", healthHandler.GetHealth)                               // GET /api/v1/health
+		v1.GET("/health/live", healthHandler.GetLiveness)                        // GET /api/v1/health/live
+		v1.GET("/health/ready", healthHandler.GetReadiness)                      // GET /api/v1/health/ready
+		v1.GET("/health/startup", healthHandler.GetStartup)                      // GET /api/v1/health/startup
+		v1.GET("/health/ping", healthHandler.GetHealthSimple)                    // GET /api/v1/health/ping
+		v1.GET("/health/history", healthHandler.GetHealthHistory)                // GET /api/v1/health/history
+		v1.GET("/health/component/:component", healthHandler.GetComponentHealth) // GET /api/v1/health/component/{name}
+	}
+
+	// Operator-only endpoints, disabled unless ADMIN_TOKEN is set
+	admin := v1.Group("/admin", middleware.AdminAuth(cfg.Admin.Token))
+	{
+		admin.DELETE("/posts/:id", postHandler.PurgePost)
+		admin.GET("/config", configHandler.GetConfig)
+		admin.GET("/log-level", logLevelHandler.GetLogLevel)
+		admin.PUT("/log-level", logLevelHandler.SetLogLevel)
+	}
+
+	// Keep infrastructure health endpoints for Kubernetes probes (no versioning)
+	router.GET("/health/live", healthHandler.GetLiveness)
+	router.GET("/health/ready", healthHandler.GetReadiness)
+	router.GET("/health/startup", healthHandler.GetStartup)
+
+	// Prometheus scrape endpoint, unversioned like the probes
+	router.GET("/metrics", gin.WrapH(a.metrics.Handler()))
+
+	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+
+	return router
+}
+
+// serveUntilSignalled waits until signalled is cancelled by SIGINT or SIGTERM,
+// or the server, which is already listening, fails with an error sent on
+// errs. On a signal it drains the server: readiness fails first so Kubernetes
+// stops routing to the pod, the server keeps serving for the drain period
+// while that propagates, and then it stops accepting connections and waits
+// for in-flight requests to finish.
+func serveUntilSignalled(signalled context.Context, server *http.Server, errs <-chan error, health service.HealthService, cfg config.ServerConfig) error {
+	select {
+	case err := <-errs:
+		return err
+	case <-signalled.Done():
+	}
+	// Restore the default handlers, so a second signal exits immediately
+	signal.Reset(syscall.SIGINT, syscall.SIGTERM)
+
+	slog.Info("Shutting down", "drain_period", cfg.ShutdownDrain.String())
+	health.MarkShuttingDown()
+	time.Sleep(cfg.ShutdownDrain)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		server.Close()
+		return fmt.Errorf("in-flight requests did not finish in %s: %w", cfg.ShutdownTimeout, err)
+	}
+
+	slog.Info("Server stopped")
+	return nil
+}
@@ -15,39 +15,90 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/posts": {
+        "/admin/config": {
             "get": {
-                "description": "Get a list of all posts",
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Show the configuration the server is running with, by section and key. Secrets are redacted. Requires the admin token.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "posts"
+                    "admin"
                 ],
-                "summary": "Get all posts",
+                "summary": "Show the effective configuration",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Post"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "object",
+                                "additionalProperties": true
                             }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/log-level": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Show the level below which log records are dropped. Requires the admin token.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Show the log level",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.LogLevel"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
                         }
                     }
                 }
             },
-            "post": {
-                "description": "Create a new post with title, content, and author",
+            "put": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Change the log level of this instance until it restarts, such as to debug to see every SQL statement. Requires the admin token.",
                 "consumes": [
                     "application/json"
                 ],
@@ -55,58 +106,60 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "posts"
+                    "admin"
                 ],
-                "summary": "Create a new post",
+                "summary": "Change the log level",
                 "parameters": [
                     {
-                        "description": "Post data",
-                        "name": "post",
+                        "description": "New level",
+                        "name": "level",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.CreatePostRequest"
+                            "$ref": "#/definitions/models.LogLevel"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Post"
+                            "$ref": "#/definitions/models.LogLevel"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.Problem"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
                         }
                     }
                 }
             }
         },
-        "/posts/{id}": {
-            "get": {
-                "description": "Get a single post by its ID",
-                "produces": [
-                    "application/json"
+        "/admin/posts/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
                 ],
+                "description": "Permanently remove a post that is already in the trash. Requires the admin token.",
                 "tags": [
-                    "posts"
+                    "admin"
                 ],
-                "summary": "Get a post by ID",
+                "summary": "Permanently delete a post",
                 "parameters": [
                     {
                         "type": "string",
@@ -117,110 +170,184 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/models.Post"
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.Problem"
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Update an existing post by ID",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Get detailed health information including all component statuses. With Accept: text/plain the report is a Nagios-style line, and with format=prometheus it is a set of gauges in the Prometheus text format, always served with 200.",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/plain"
                 ],
                 "tags": [
-                    "posts"
+                    "health"
                 ],
-                "summary": "Update a post",
+                "summary": "Get comprehensive health status",
                 "parameters": [
                     {
+                        "enum": [
+                            "json",
+                            "text",
+                            "prometheus"
+                        ],
                         "type": "string",
-                        "description": "Post ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Updated post data",
-                        "name": "post",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.UpdatePostRequest"
-                        }
+                        "description": "Output format instead of content negotiation",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Service is healthy",
                         "schema": {
-                            "$ref": "#/definitions/models.Post"
+                            "$ref": "#/definitions/models.HealthResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Unknown format",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service is degraded or unhealthy",
+                        "schema": {
+                            "$ref": "#/definitions/models.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health/component/{component}": {
+            "get": {
+                "description": "Get health status of a specific component",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Get specific component health",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Registered component name, such as database, memory or goroutines",
+                        "name": "component",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Component is healthy",
+                        "schema": {
+                            "$ref": "#/definitions/models.ComponentHealth"
                         }
                     },
                     "404": {
-                        "description": "Not Found",
+                        "description": "No such component",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.Problem"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "503": {
+                        "description": "Component is degraded or unhealthy",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.ComponentHealth"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete a post by ID",
+            }
+        },
+        "/health/history": {
+            "get": {
+                "description": "List the recent check results of every component, oldest first, and whether each component is flapping between statuses",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "posts"
+                    "health"
                 ],
-                "summary": "Delete a post",
+                "summary": "Get recent health check results",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Post ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Only this component",
+                        "name": "component",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.HealthHistoryResponse"
+                        }
                     },
                     "404": {
-                        "description": "Not Found",
+                        "description": "No such component",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/health/live": {
+            "get": {
+                "description": "Kubernetes liveness probe - indicates if the service is alive",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness probe endpoint",
+                "responses": {
+                    "200": {
+                        "description": "Service is alive",
+                        "schema": {
+                            "$ref": "#/definitions/models.LivenessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health/ping": {
+            "get": {
+                "description": "Simple health check that returns OK if service is running",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Simple health check",
+                "responses": {
+                    "200": {
+                        "description": "Service is OK",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -230,50 +357,1739 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.CreatePostRequest": {
-            "type": "object",
-            "required": [
-                "author",
-                "content",
-                "title"
-            ],
-            "properties": {
-                "author": {
-                    "type": "string",
-                    "example": "Jane Doe"
-                },
-                "content": {
-                    "type": "string",
-                    "example": "Post content goes here"
-                },
-                "title": {
-                    "type": "string",
-                    "example": "New Post Title"
+        },
+        "/health/ready": {
+            "get": {
+                "description": "Kubernetes readiness probe - indicates if the service is ready to accept traffic. A degraded critical component, such as a slow database, still passes: every replica shares it, so failing would pull them all from the load balancer at once.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness probe endpoint",
+                "responses": {
+                    "200": {
+                        "description": "Service is ready, possibly degraded",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReadinessResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service is not ready",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReadinessResponse"
+                        }
+                    }
                 }
             }
         },
-        "models.Post": {
-            "type": "object",
-            "properties": {
-                "author": {
-                    "type": "string",
-                    "example": "John Doe"
-                },
-                "content": {
-                    "type": "string",
-                    "example": "This is the content of the post"
+        "/health/startup": {
+            "get": {
+                "description": "Kubernetes startup probe - reports the progress of the boot phases: loading config, connecting to the database, running migrations and warming up. The server answers while booting, so the probe sees starting rather than a refused connection.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Startup probe endpoint",
+                "responses": {
+                    "200": {
+                        "description": "Every phase is complete",
+                        "schema": {
+                            "$ref": "#/definitions/models.StartupResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service is starting or a phase failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.StartupResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts": {
+            "get": {
+                "description": "Get a filtered and sorted page of published posts. Pass next_cursor from the previous page to fetch the next one.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Get all posts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only posts by this author",
+                        "name": "author",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only posts created after this RFC 3339 timestamp",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only posts created before this RFC 3339 timestamp",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only posts updated at or after this RFC 3339 timestamp",
+                        "name": "updated_since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only posts with these tags; repeat the parameter for several tags",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "any",
+                            "all"
+                        ],
+                        "type": "string",
+                        "default": "any",
+                        "description": "Whether posts need any or all of the tags",
+                        "name": "tag_match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-created_at",
+                        "description": "Comma separated sort fields (title, author, created_at, updated_at); prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor returned as next_cursor by the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PostPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new post with title, content, and author. Posts start as drafts unless status is published or a publish_at time is given.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Create a new post",
+                "parameters": [
+                    {
+                        "description": "Post data",
+                        "name": "post",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreatePostRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Post"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Post version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/by-slug/{slug}": {
+            "get": {
+                "description": "Get a single post by its slug. Slugs the post had before a title change redirect to its current slug.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Get a post by slug",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Post"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Post version, to be sent back in If-Match"
+                            }
+                        }
+                    },
+                    "301": {
+                        "description": "The slug is outdated; Location holds the current one",
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the post under its current slug"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/search": {
+            "get": {
+                "description": "Full-text search over published post titles and content, ranked by relevance with highlighted snippets. Supports quoted phrases, \"or\" and \"-\" exclusions.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Search posts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of results to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PostSearchResults"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/trash": {
+            "get": {
+                "description": "Get a page of posts in the trash, most recently deleted first. Accepts the same filters as the post listing and may additionally sort by deleted_at.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "List deleted posts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only posts by this author",
+                        "name": "author",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only posts created after this RFC 3339 timestamp",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only posts created before this RFC 3339 timestamp",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only posts updated at or after this RFC 3339 timestamp",
+                        "name": "updated_since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-deleted_at",
+                        "description": "Comma separated sort fields (title, author, created_at, updated_at, deleted_at); prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor returned as next_cursor by the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PostPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{id}": {
+            "get": {
+                "description": "Get a single post by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Get a post by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Post"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Post version, to be sent back in If-Match"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update an existing post by ID. Send the ETag from a previous read in If-Match to avoid overwriting concurrent changes.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Update a post",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being updated",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Updated post data",
+                        "name": "post",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdatePostRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Post"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New post version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Move a post to the trash by ID. It can be brought back with the restore endpoint. Send the ETag from a previous read in If-Match to avoid deleting a post that changed since.",
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Delete a post",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being deleted",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{id}/archive": {
+            "post": {
+                "description": "Archive a draft, scheduled or published post, removing it from the public listing",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lifecycle"
+                ],
+                "summary": "Archive a post",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being changed",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Post"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New post version"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{id}/comments": {
+            "get": {
+                "description": "Get a page of top-level comments on a post, oldest first, each with its replies nested underneath. Deleted comments are kept as tombstones so their replies stay in place.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "List comments on a post",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Top-level comments per page (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor returned as next_cursor by the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.CommentPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add a comment to a published post. Set parent_id to reply to another comment on the same post.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Comment on a post",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Comment data",
+                        "name": "comment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateCommentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Comment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{id}/comments/{comment_id}": {
+            "get": {
+                "description": "Get a single comment on a post, without its replies",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Get a comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comment ID",
+                        "name": "comment_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Comment"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace the body of a comment. Deleted comments cannot be edited.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Edit a comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comment ID",
+                        "name": "comment_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New comment body",
+                        "name": "comment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateCommentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Comment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Replace a comment with a tombstone. Its replies stay in the thread.",
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Delete a comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comment ID",
+                        "name": "comment_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{id}/publish": {
+            "post": {
+                "description": "Publish a draft, scheduled or archived post immediately, or schedule it by passing a future publish_at",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lifecycle"
+                ],
+                "summary": "Publish a post",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being changed",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Optional publish time",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.PublishPostRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Post"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New post version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{id}/restore": {
+            "post": {
+                "description": "Move a post out of the trash",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Restore a deleted post",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Post"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New post version"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{id}/revisions": {
+            "get": {
+                "description": "Get every revision of a post, newest first. Revision numbers match the post version they produced.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "revisions"
+                ],
+                "summary": "List post revisions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PostRevision"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{id}/revisions/diff": {
+            "get": {
+                "description": "Get the line-level difference of title, author and content between two revisions of a post",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "revisions"
+                ],
+                "summary": "Diff two post revisions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Base revision number",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Target revision number",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RevisionDiff"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{id}/revisions/{rev}": {
+            "get": {
+                "description": "Get a single revision of a post",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "revisions"
+                ],
+                "summary": "Get a post revision",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision number",
+                        "name": "rev",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PostRevision"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{id}/revisions/{rev}/restore": {
+            "post": {
+                "description": "Restore the title, content and author of an earlier revision. The rollback is recorded as a new revision.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "revisions"
+                ],
+                "summary": "Roll a post back to a revision",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision number to restore",
+                        "name": "rev",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being replaced",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Editor performing the rollback",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.RestoreRevisionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Post"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New post version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{id}/unpublish": {
+            "post": {
+                "description": "Move a published or scheduled post back to draft",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lifecycle"
+                ],
+                "summary": "Unpublish a post",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being changed",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Post"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New post version"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags": {
+            "get": {
+                "description": "Get tags with the number of published posts carrying them, most used first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "List tags",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of tags (default 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.TagCount"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.Comment": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "Jane Doe"
+                },
+                "body": {
+                    "type": "string",
+                    "example": "Great post!"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
+                },
+                "deleted_at": {
+                    "description": "DeletedAt marks a tombstone: the author and body are cleared but the\ncomment stays in place so its replies keep their thread",
+                    "type": "string",
+                    "example": "2023-01-02T00:00:00Z"
+                },
+                "depth": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "id": {
+                    "type": "string",
+                    "example": "6f1e2d3c-4b5a-4978-8695-a4b3c2d1e0f9"
+                },
+                "parent_id": {
+                    "type": "string",
+                    "example": "5e0d1c2b-3a49-4867-9584-93a2b1c0d9e8"
+                },
+                "post_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "replies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Comment"
+                    }
+                },
+                "root_id": {
+                    "type": "string",
+                    "example": "5e0d1c2b-3a49-4867-9584-93a2b1c0d9e8"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
+                }
+            }
+        },
+        "models.CommentPage": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Comment"
+                    }
+                },
+                "page": {
+                    "$ref": "#/definitions/models.PageInfo"
+                }
+            }
+        },
+        "models.ComponentHealth": {
+            "type": "object",
+            "properties": {
+                "checked_at": {
+                    "description": "CheckedAt is when the check ran; Stale is set when the background\nprobe has not refreshed the result for longer than expected",
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
+                },
+                "details": {
+                    "description": "Details holds numbers wherever a detail is a measurement, so they can\nbe exported as metrics, and strings otherwise",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "error": {
+                    "type": "string",
+                    "example": "connection timeout"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Connection successful"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "database"
+                },
+                "response_time_ms": {
+                    "type": "integer",
+                    "example": 15
+                },
+                "stale": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.HealthStatus"
+                        }
+                    ],
+                    "example": "healthy"
+                }
+            }
+        },
+        "models.ComponentHistory": {
+            "type": "object",
+            "properties": {
+                "flapping": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "name": {
+                    "type": "string",
+                    "example": "database"
+                },
+                "samples": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.HealthSample"
+                    }
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.HealthStatus"
+                        }
+                    ],
+                    "example": "healthy"
+                },
+                "transitions": {
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "models.CreateCommentRequest": {
+            "type": "object",
+            "required": [
+                "author",
+                "body"
+            ],
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "Jane Doe"
+                },
+                "body": {
+                    "type": "string",
+                    "example": "Great post!"
+                },
+                "parent_id": {
+                    "description": "ParentID makes the comment a reply to another comment on the same post",
+                    "type": "string",
+                    "example": "5e0d1c2b-3a49-4867-9584-93a2b1c0d9e8"
+                }
+            }
+        },
+        "models.CreatePostRequest": {
+            "type": "object",
+            "required": [
+                "author",
+                "content",
+                "title"
+            ],
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "Jane Doe"
+                },
+                "content": {
+                    "type": "string",
+                    "example": "Post content goes here"
+                },
+                "publish_at": {
+                    "type": "string",
+                    "example": "2023-01-01T09:00:00Z"
+                },
+                "status": {
+                    "description": "Status is draft unless set to published; a future PublishAt schedules the post",
+                    "enum": [
+                        "draft",
+                        "published"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.PostStatus"
+                        }
+                    ],
+                    "example": "draft"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "go",
+                        "kubernetes"
+                    ]
+                },
+                "title": {
+                    "type": "string",
+                    "example": "New Post Title"
+                }
+            }
+        },
+        "models.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "title"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "is required"
+                }
+            }
+        },
+        "models.HealthHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "components": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ComponentHistory"
+                    }
+                },
+                "interval_seconds": {
+                    "type": "number",
+                    "example": 10
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
+                }
+            }
+        },
+        "models.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "components": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ComponentHealth"
+                    }
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.HealthStatus"
+                        }
+                    ],
+                    "example": "healthy"
+                },
+                "summary": {
+                    "$ref": "#/definitions/models.HealthSummary"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
+                },
+                "uptime_seconds": {
+                    "type": "integer",
+                    "example": 3600
+                },
+                "version": {
+                    "type": "string",
+                    "example": "1.0.0"
+                }
+            }
+        },
+        "models.HealthSample": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "connection timeout"
+                },
+                "response_time_ms": {
+                    "type": "integer",
+                    "example": 15
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.HealthStatus"
+                        }
+                    ],
+                    "example": "healthy"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
+                }
+            }
+        },
+        "models.HealthStatus": {
+            "type": "string",
+            "enum": [
+                "healthy",
+                "degraded",
+                "unhealthy"
+            ],
+            "x-enum-varnames": [
+                "HealthStatusHealthy",
+                "HealthStatusDegraded",
+                "HealthStatusUnhealthy"
+            ]
+        },
+        "models.HealthSummary": {
+            "type": "object",
+            "properties": {
+                "degraded": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "healthy": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "total_components": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "unhealthy": {
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "models.LivenessResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Service is alive"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.HealthStatus"
+                        }
+                    ],
+                    "example": "healthy"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
+                }
+            }
+        },
+        "models.LogLevel": {
+            "type": "object",
+            "required": [
+                "level"
+            ],
+            "properties": {
+                "level": {
+                    "type": "string",
+                    "enum": [
+                        "debug",
+                        "info",
+                        "warn",
+                        "error"
+                    ],
+                    "example": "info"
+                }
+            }
+        },
+        "models.PageInfo": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "has_more": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "limit": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "example": "eyJzIjoiLWNyZWF0ZWRfYXQiLCJ2IjpbIjIwMjMtMDEtMDFUMDA6MDA6MDBaIl0sImlkIjoiMTIzIn0"
+                }
+            }
+        },
+        "models.Post": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "content": {
+                    "type": "string",
+                    "example": "This is the content of the post"
                 },
                 "created_at": {
                     "type": "string",
                     "example": "2023-01-01T00:00:00Z"
                 },
+                "deleted_at": {
+                    "description": "DeletedAt is set when the post is moved to the trash",
+                    "type": "string",
+                    "format": "date-time",
+                    "example": "2023-01-02T00:00:00Z"
+                },
                 "id": {
                     "type": "string",
                     "example": "123e4567-e89b-12d3-a456-426614174000"
                 },
+                "publish_at": {
+                    "description": "PublishAt is when a scheduled post goes live, or when a published post went live",
+                    "type": "string",
+                    "example": "2023-01-01T09:00:00Z"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "sample-post-title"
+                },
+                "status": {
+                    "description": "Status defaults to published in the database so rows that predate the\nlifecycle stay visible; new posts always set it explicitly",
+                    "enum": [
+                        "draft",
+                        "scheduled",
+                        "published",
+                        "archived"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.PostStatus"
+                        }
+                    ],
+                    "example": "published"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "go",
+                        "kubernetes"
+                    ]
+                },
                 "title": {
                     "type": "string",
                     "example": "Sample Post Title"
@@ -281,6 +2097,359 @@ const docTemplate = `{
                 "updated_at": {
                     "type": "string",
                     "example": "2023-01-01T00:00:00Z"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.PostPage": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Post"
+                    }
+                },
+                "page": {
+                    "$ref": "#/definitions/models.PageInfo"
+                }
+            }
+        },
+        "models.PostRevision": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "content": {
+                    "type": "string",
+                    "example": "This is the content of the post"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
+                },
+                "editor": {
+                    "type": "string",
+                    "example": "Jane Doe"
+                },
+                "post_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "revision": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Sample Post Title"
+                }
+            }
+        },
+        "models.PostSearchHit": {
+            "type": "object",
+            "properties": {
+                "content_highlight": {
+                    "type": "string",
+                    "example": "... writing your first \u003cmark\u003eGo\u003c/mark\u003e program ..."
+                },
+                "post": {
+                    "$ref": "#/definitions/models.Post"
+                },
+                "rank": {
+                    "type": "number",
+                    "example": 0.0759
+                },
+                "title_highlight": {
+                    "type": "string",
+                    "example": "Getting started with \u003cmark\u003eGo\u003c/mark\u003e"
+                }
+            }
+        },
+        "models.PostSearchResults": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PostSearchHit"
+                    }
+                },
+                "page": {
+                    "$ref": "#/definitions/models.SearchPageInfo"
+                },
+                "query": {
+                    "type": "string",
+                    "example": "go tips"
+                }
+            }
+        },
+        "models.PostStatus": {
+            "type": "string",
+            "enum": [
+                "draft",
+                "scheduled",
+                "published",
+                "archived"
+            ],
+            "x-enum-varnames": [
+                "PostStatusDraft",
+                "PostStatusScheduled",
+                "PostStatusPublished",
+                "PostStatusArchived"
+            ]
+        },
+        "models.Problem": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string",
+                    "example": "post not found"
+                },
+                "errors": {
+                    "description": "Errors lists the rejected fields of a validation problem",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FieldError"
+                    }
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/api/v1/posts/123e4567-e89b-12d3-a456-426614174000"
+                },
+                "request_id": {
+                    "description": "RequestID matches the X-Request-ID response header",
+                    "type": "string",
+                    "example": "4f9c2a7e-3b1d-4c8e-9f0a-6d2e1b7c5a3f"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Not Found"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "about:blank"
+                }
+            }
+        },
+        "models.PublishPostRequest": {
+            "type": "object",
+            "properties": {
+                "publish_at": {
+                    "description": "PublishAt schedules the post instead of publishing it immediately",
+                    "type": "string",
+                    "example": "2023-01-01T09:00:00Z"
+                }
+            }
+        },
+        "models.ReadinessResponse": {
+            "type": "object",
+            "properties": {
+                "components": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ComponentHealth"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Service is ready"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.HealthStatus"
+                        }
+                    ],
+                    "example": "healthy"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
+                }
+            }
+        },
+        "models.RestoreRevisionRequest": {
+            "type": "object",
+            "properties": {
+                "editor": {
+                    "type": "string",
+                    "example": "Jane Doe"
+                }
+            }
+        },
+        "models.RevisionDiff": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/utils.DiffLine"
+                    }
+                },
+                "content": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/utils.DiffLine"
+                    }
+                },
+                "from": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "post_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "title": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/utils.DiffLine"
+                    }
+                },
+                "to": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "models.SearchPageInfo": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "has_more": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "limit": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "offset": {
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "models.StartupPhase": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "description": "Attempts counts tries of phases that are retried, such as connecting\nto the database, and Error is the latest failure",
+                    "type": "integer",
+                    "example": 1
+                },
+                "completed_at": {
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "connection refused"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "migrations"
+                },
+                "started_at": {
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.StartupPhaseStatus"
+                        }
+                    ],
+                    "example": "running"
+                }
+            }
+        },
+        "models.StartupPhaseStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "running",
+                "complete",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "StartupPhasePending",
+                "StartupPhaseRunning",
+                "StartupPhaseComplete",
+                "StartupPhaseFailed"
+            ]
+        },
+        "models.StartupResponse": {
+            "type": "object",
+            "properties": {
+                "phases": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StartupPhase"
+                    }
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.StartupStatus"
+                        }
+                    ],
+                    "example": "starting"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
+                }
+            }
+        },
+        "models.StartupStatus": {
+            "type": "string",
+            "enum": [
+                "starting",
+                "started",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "StartupStatusStarting",
+                "StartupStatusStarted",
+                "StartupStatusFailed"
+            ]
+        },
+        "models.TagCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "name": {
+                    "type": "string",
+                    "example": "go"
+                }
+            }
+        },
+        "models.UpdateCommentRequest": {
+            "type": "object",
+            "required": [
+                "body"
+            ],
+            "properties": {
+                "body": {
+                    "type": "string",
+                    "example": "Great post, thanks!"
                 }
             }
         },
@@ -295,11 +2464,70 @@ const docTemplate = `{
                     "type": "string",
                     "example": "Updated post content"
                 },
+                "editor": {
+                    "description": "Editor is recorded on the revision written by the update and defaults to the author",
+                    "type": "string",
+                    "example": "Jane Doe"
+                },
+                "tags": {
+                    "description": "Tags replaces the post's tags when present; an empty array removes them all",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "go",
+                        "kubernetes"
+                    ]
+                },
                 "title": {
                     "type": "string",
                     "example": "Updated Post Title"
                 }
             }
+        },
+        "utils.DiffLine": {
+            "type": "object",
+            "properties": {
+                "op": {
+                    "enum": [
+                        "equal",
+                        "insert",
+                        "delete"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/utils.DiffOp"
+                        }
+                    ],
+                    "example": "insert"
+                },
+                "text": {
+                    "type": "string",
+                    "example": "A line that was added"
+                }
+            }
+        },
+        "utils.DiffOp": {
+            "type": "string",
+            "enum": [
+                "equal",
+                "insert",
+                "delete"
+            ],
+            "x-enum-varnames": [
+                "DiffEqual",
+                "DiffInsert",
+                "DiffDelete"
+            ]
+        }
+    },
+    "securityDefinitions": {
+        "AdminToken": {
+            "description": "Admin bearer token, sent as \"Bearer \u003cADMIN_TOKEN\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
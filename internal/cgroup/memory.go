@@ -0,0 +1,121 @@
+// Package cgroup reads the memory accounting of the container the process
+// runs in, from cgroup v2 or v1
+package cgroup
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultRoot is where the cgroup filesystem is mounted
+const DefaultRoot = "/sys/fs/cgroup"
+
+// unlimited is the smallest limit treated as no limit; cgroup v1 reports a
+// missing limit as the largest page-aligned int64
+const unlimited = 1 << 60
+
+// ErrNoCgroup is returned when no cgroup memory controller can be found
+var ErrNoCgroup = errors.New("no cgroup memory controller found")
+
+// Memory is the memory accounting of a cgroup
+type Memory struct {
+	// Limit is the memory limit in bytes, or 0 when there is none
+	Limit uint64
+	// WorkingSet is usage minus inactive file cache, which the kernel can
+	// reclaim. It is what the OOM killer and kubectl top go by.
+	WorkingSet uint64
+}
+
+// ReadMemory reads the memory accounting of the current process's cgroup
+// under root, trying cgroup v2 first
+func ReadMemory(root string) (Memory, error) {
+	if dir, ok := v2Dir(root); ok {
+		return readMemory(dir, "memory.max", "memory.current", "inactive_file")
+	}
+	dir := filepath.Join(root, "memory")
+	if _, err := os.Stat(filepath.Join(dir, "memory.usage_in_bytes")); err == nil {
+		return readMemory(dir, "memory.limit_in_bytes", "memory.usage_in_bytes", "total_inactive_file")
+	}
+	return Memory{}, ErrNoCgroup
+}
+
+// v2Dir finds the unified hierarchy directory of the current process. With
+// a cgroup namespace, as in containers, that is the root itself.
+func v2Dir(root string) (string, bool) {
+	candidates := []string{root}
+	if data, err := os.ReadFile("/proc/self/cgroup"); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if path, ok := strings.CutPrefix(line, "0::"); ok {
+				candidates = append([]string{filepath.Join(root, path)}, candidates...)
+			}
+		}
+	}
+
+	for _, dir := range candidates {
+		if _, err := os.Stat(filepath.Join(dir, "memory.current")); err == nil {
+			return dir, true
+		}
+	}
+	return "", false
+}
+
+func readMemory(dir, limitFile, usageFile, inactiveKey string) (Memory, error) {
+	var mem Memory
+
+	limit, err := os.ReadFile(filepath.Join(dir, limitFile))
+	if err != nil {
+		return mem, err
+	}
+	if value := strings.TrimSpace(string(limit)); value != "max" {
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return mem, err
+		}
+		if n < unlimited {
+			mem.Limit = n
+		}
+	}
+
+	usage, err := readUint(filepath.Join(dir, usageFile))
+	if err != nil {
+		return mem, err
+	}
+	inactive, err := readStat(filepath.Join(dir, "memory.stat"), inactiveKey)
+	if err != nil {
+		return mem, err
+	}
+	if inactive < usage {
+		mem.WorkingSet = usage - inactive
+	}
+	return mem, nil
+}
+
+func readUint(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// readStat returns a counter from a memory.stat file, or 0 if it is missing
+func readStat(path, key string) (uint64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		name, value, ok := strings.Cut(scanner.Text(), " ")
+		if ok && name == key {
+			return strconv.ParseUint(value, 10, 64)
+		}
+	}
+	return 0, scanner.Err()
+}
@@ -0,0 +1,227 @@
+// Package config loads the service configuration. Every setting has a
+// default, and can be overridden, in increasing order of precedence, by an
+// optional YAML file, an environment variable and a command line flag.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"postService/internal/logging"
+)
+
+// Config is the complete configuration of the service. The yaml tags name
+// the settings in the config file and in flags (as "section.key"), the env
+// tags name their environment variables, and secret settings are never
+// shown in full.
+type Config struct {
+	Server    ServerConfig    `yaml:"server"`
+	Log       LogConfig       `yaml:"log"`
+	Database  DatabaseConfig  `yaml:"database"`
+	Scheduler SchedulerConfig `yaml:"scheduler"`
+	Health    HealthConfig    `yaml:"health"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+	Admin     AdminConfig     `yaml:"admin"`
+}
+
+type ServerConfig struct {
+	Port           string        `yaml:"port" env:"PORT"`
+	RequestTimeout time.Duration `yaml:"request_timeout" env:"REQUEST_TIMEOUT"`
+	// ShutdownDrain is how long the server keeps serving after failing
+	// readiness, and ShutdownTimeout how long in-flight requests then get
+	ShutdownDrain   time.Duration `yaml:"shutdown_drain_period" env:"SHUTDOWN_DRAIN_PERIOD"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+}
+
+type LogConfig struct {
+	// Level is one of debug, info, warn or error, and can be changed at
+	// runtime through /api/v1/admin/log-level
+	Level string `yaml:"level" env:"LOG_LEVEL"`
+}
+
+type DatabaseConfig struct {
+	Host            string        `yaml:"host" env:"DB_HOST"`
+	Port            string        `yaml:"port" env:"DB_PORT"`
+	User            string        `yaml:"user" env:"DB_USER"`
+	Password        string        `yaml:"password" env:"DB_PASSWORD" secret:"true"`
+	Name            string        `yaml:"name" env:"DB_NAME"`
+	SSLMode         string        `yaml:"sslmode" env:"DB_SSLMODE"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	// SlowQueryThreshold is how long a statement may take before it is
+	// logged as a warning; every statement is logged at debug level
+	SlowQueryThreshold time.Duration `yaml:"slow_query_threshold" env:"DB_SLOW_QUERY_THRESHOLD"`
+	// ConnectTimeout is how long serve keeps retrying the first connection
+	// before giving up, so the database may start after the service
+	ConnectTimeout time.Duration `yaml:"connect_timeout" env:"DB_CONNECT_TIMEOUT"`
+}
+
+// DatabaseURL returns the connection string for the Postgres driver
+func (c DatabaseConfig) DatabaseURL() string {
+	return fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		c.Host, c.Port, c.User, c.Password, c.Name, c.SSLMode,
+	)
+}
+
+type SchedulerConfig struct {
+	Interval time.Duration `yaml:"interval" env:"PUBLISH_SCHEDULER_INTERVAL"`
+}
+
+type HealthConfig struct {
+	// Interval is how often components are checked in the background; the
+	// health endpoints serve the latest results
+	Interval time.Duration `yaml:"interval" env:"HEALTH_CHECK_INTERVAL"`
+	// HistorySize is how many results are kept per component, and a component
+	// whose status changed FlapThreshold times within them is flapping
+	HistorySize   int `yaml:"history_size" env:"HEALTH_HISTORY_SIZE"`
+	FlapThreshold int `yaml:"flap_threshold" env:"HEALTH_FLAP_THRESHOLD"`
+	// In a container with a memory limit, memory is judged by the working
+	// set as a percentage of the limit; elsewhere by the heap size in MB
+	MemoryDegradedPercent  float64 `yaml:"memory_degraded_percent" env:"HEALTH_MEMORY_DEGRADED_PERCENT"`
+	MemoryUnhealthyPercent float64 `yaml:"memory_unhealthy_percent" env:"HEALTH_MEMORY_UNHEALTHY_PERCENT"`
+	MemoryDegradedMB       uint64  `yaml:"memory_degraded_mb" env:"HEALTH_MEMORY_DEGRADED_MB"`
+	MemoryUnhealthyMB      uint64  `yaml:"memory_unhealthy_mb" env:"HEALTH_MEMORY_UNHEALTHY_MB"`
+	GoroutinesDegraded     int     `yaml:"goroutines_degraded" env:"HEALTH_GOROUTINES_DEGRADED"`
+	GoroutinesUnhealthy    int     `yaml:"goroutines_unhealthy" env:"HEALTH_GOROUTINES_UNHEALTHY"`
+	// DatabasePoolDegradedPercent is the share of max_open_conns in use at
+	// which the database degrades
+	DatabasePoolDegradedPercent float64 `yaml:"database_pool_degraded_percent" env:"HEALTH_DATABASE_POOL_DEGRADED_PERCENT"`
+	// DatabaseProbe adds a read from posts and a rolled back write to the
+	// database check, and the database degrades when the whole check takes
+	// longer than DatabaseLatencySLO
+	DatabaseProbe      bool          `yaml:"database_probe" env:"HEALTH_DATABASE_PROBE"`
+	DatabaseLatencySLO time.Duration `yaml:"database_latency_slo" env:"HEALTH_DATABASE_LATENCY_SLO"`
+}
+
+type TracingConfig struct {
+	// Exporter is one of TracingExporters
+	Exporter string `yaml:"exporter" env:"TRACING_EXPORTER"`
+	// OTLPEndpoint is the collector URL for the otlp exporter, such as
+	// http://otel-collector:4318. When empty the standard
+	// OTEL_EXPORTER_OTLP_* variables apply.
+	OTLPEndpoint string  `yaml:"otlp_endpoint" env:"TRACING_OTLP_ENDPOINT"`
+	SampleRatio  float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+}
+
+// TracingExporters lists where traces can be sent: nowhere, to stdout as
+// JSON, or to an OpenTelemetry collector over OTLP/HTTP
+var TracingExporters = []string{"none", "stdout", "otlp"}
+
+type AdminConfig struct {
+	// Token guards /api/v1/admin; admin endpoints are disabled when it is empty
+	Token string `yaml:"token" env:"ADMIN_TOKEN" secret:"true"`
+}
+
+// Default returns the configuration used when nothing is overridden
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:            "8080",
+			RequestTimeout:  10 * time.Second,
+			ShutdownDrain:   5 * time.Second,
+			ShutdownTimeout: 20 * time.Second,
+		},
+		Log: LogConfig{
+			Level: "info",
+		},
+		Database: DatabaseConfig{
+			Host:               "localhost",
+			Port:               "5432",
+			User:               "postgres",
+			Password:           "postgres",
+			Name:               "postservice",
+			SSLMode:            "disable",
+			MaxIdleConns:       10,
+			MaxOpenConns:       100,
+			ConnMaxLifetime:    time.Hour,
+			SlowQueryThreshold: 200 * time.Millisecond,
+			ConnectTimeout:     2 * time.Minute,
+		},
+		Scheduler: SchedulerConfig{
+			Interval: 30 * time.Second,
+		},
+		Health: HealthConfig{
+			Interval:                    10 * time.Second,
+			HistorySize:                 60,
+			FlapThreshold:               4,
+			MemoryDegradedPercent:       80,
+			MemoryUnhealthyPercent:      95,
+			MemoryDegradedMB:            512,
+			MemoryUnhealthyMB:           1024,
+			GoroutinesDegraded:          1000,
+			GoroutinesUnhealthy:         5000,
+			DatabasePoolDegradedPercent: 80,
+			DatabaseLatencySLO:          100 * time.Millisecond,
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			SampleRatio: 1,
+		},
+	}
+}
+
+var sslModes = map[string]bool{
+	"disable": true, "allow": true, "prefer": true, "require": true, "verify-ca": true, "verify-full": true,
+}
+
+// Validate reports every invalid setting at once
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(validPort(c.Server.Port), "server.port: %q is not a valid port", c.Server.Port)
+	check(c.Server.RequestTimeout >= 0, "server.request_timeout: must not be negative")
+	check(c.Server.ShutdownDrain >= 0, "server.shutdown_drain_period: must not be negative")
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout: must be positive")
+
+	_, err := logging.ParseLevel(c.Log.Level)
+	check(err == nil, "log.level: %v", err)
+
+	check(c.Database.Host != "", "database.host: is required")
+	check(validPort(c.Database.Port), "database.port: %q is not a valid port", c.Database.Port)
+	check(c.Database.User != "", "database.user: is required")
+	check(c.Database.Name != "", "database.name: is required")
+	check(sslModes[c.Database.SSLMode], "database.sslmode: %q is not a Postgres sslmode", c.Database.SSLMode)
+	check(c.Database.MaxOpenConns > 0, "database.max_open_conns: must be positive")
+	check(c.Database.MaxIdleConns >= 0 && c.Database.MaxIdleConns <= c.Database.MaxOpenConns,
+		"database.max_idle_conns: must be between 0 and max_open_conns")
+	check(c.Database.ConnMaxLifetime >= 0, "database.conn_max_lifetime: must not be negative")
+	check(c.Database.SlowQueryThreshold >= 0, "database.slow_query_threshold: must not be negative")
+	check(c.Database.ConnectTimeout > 0, "database.connect_timeout: must be positive")
+
+	check(c.Scheduler.Interval > 0, "scheduler.interval: must be positive")
+
+	check(c.Health.Interval > 0, "health.interval: must be positive")
+	check(c.Health.HistorySize > 0, "health.history_size: must be positive")
+	check(c.Health.FlapThreshold > 0, "health.flap_threshold: must be positive")
+	check(c.Health.MemoryDegradedPercent > 0 && c.Health.MemoryDegradedPercent < c.Health.MemoryUnhealthyPercent,
+		"health.memory_degraded_percent: must be positive and below memory_unhealthy_percent")
+	check(c.Health.MemoryUnhealthyPercent <= 100, "health.memory_unhealthy_percent: must be at most 100")
+	check(c.Health.MemoryDegradedMB < c.Health.MemoryUnhealthyMB,
+		"health.memory_degraded_mb: must be below memory_unhealthy_mb")
+	check(c.Health.GoroutinesDegraded > 0 && c.Health.GoroutinesDegraded < c.Health.GoroutinesUnhealthy,
+		"health.goroutines_degraded: must be positive and below goroutines_unhealthy")
+	check(c.Health.DatabasePoolDegradedPercent > 0 && c.Health.DatabasePoolDegradedPercent <= 100,
+		"health.database_pool_degraded_percent: must be between 0 and 100")
+	check(c.Health.DatabaseLatencySLO > 0, "health.database_latency_slo: must be positive")
+
+	check(slices.Contains(TracingExporters, c.Tracing.Exporter),
+		"tracing.exporter: %q is not one of %v", c.Tracing.Exporter, TracingExporters)
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio: must be between 0 and 1")
+
+	return errors.Join(errs...)
+}
+
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n > 0 && n <= 65535
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(c *Config)
+		want   string
+	}{
+		{"port", func(c *Config) { c.Server.Port = "http" }, `server.port: "http" is not a valid port`},
+		{"port range", func(c *Config) { c.Server.Port = "70000" }, `server.port: "70000" is not a valid port`},
+		{"shutdown timeout", func(c *Config) { c.Server.ShutdownTimeout = 0 }, "server.shutdown_timeout: must be positive"},
+		{"log level", func(c *Config) { c.Log.Level = "INFO" }, `log.level: "INFO" is not one of debug, info, warn, error`},
+		{"database host", func(c *Config) { c.Database.Host = "" }, "database.host: is required"},
+		{"sslmode", func(c *Config) { c.Database.SSLMode = "on" }, `database.sslmode: "on" is not a Postgres sslmode`},
+		{"idle above open", func(c *Config) { c.Database.MaxIdleConns = c.Database.MaxOpenConns + 1 },
+			"database.max_idle_conns: must be between 0 and max_open_conns"},
+		{"memory thresholds", func(c *Config) { c.Health.MemoryDegradedPercent = 99 },
+			"health.memory_degraded_percent: must be positive and below memory_unhealthy_percent"},
+		{"latency SLO", func(c *Config) { c.Health.DatabaseLatencySLO = -time.Second }, "health.database_latency_slo: must be positive"},
+		{"exporter", func(c *Config) { c.Tracing.Exporter = "zipkin" }, `tracing.exporter: "zipkin" is not one of [none stdout otlp]`},
+		{"sample ratio", func(c *Config) { c.Tracing.SampleRatio = 1.5 }, "tracing.sample_ratio: must be between 0 and 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.change(cfg)
+			err := cfg.Validate()
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("got error %v, want %q alone", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateReportsEverything(t *testing.T) {
+	if err := Default().Validate(); err != nil {
+		t.Fatalf("defaults are invalid: %v", err)
+	}
+
+	cfg := Default()
+	cfg.Server.Port = ""
+	cfg.Database.User = ""
+	cfg.Scheduler.Interval = 0
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("invalid configuration passed")
+	}
+	if lines := strings.Split(err.Error(), "\n"); len(lines) != 3 {
+		t.Errorf("got %d errors, want 3: %v", len(lines), err)
+	}
+}
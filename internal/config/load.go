@@ -0,0 +1,233 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileEnv names the environment variable holding the YAML file path
+// when the --config flag is not given
+const ConfigFileEnv = "CONFIG_FILE"
+
+const redacted = "<redacted>"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Loader gathers the sources a Config is loaded from
+type Loader struct {
+	path  string
+	flags []flagValue
+}
+
+type flagValue struct {
+	key, value string
+}
+
+// NewLoader returns a loader that reads flags from fs once it is parsed:
+// --config for the YAML file, and one flag per setting named after its
+// section and key, such as --database.max_open_conns. fs may be nil.
+func NewLoader(fs *flag.FlagSet) *Loader {
+	l := &Loader{}
+	if fs == nil {
+		return l
+	}
+
+	fs.StringVar(&l.path, "config", "", "YAML config file (default $"+ConfigFileEnv+")")
+	for _, s := range settings(Default()) {
+		key := s.key
+		fs.Func(key, "overrides $"+s.env, func(value string) error {
+			l.flags = append(l.flags, flagValue{key: key, value: value})
+			return nil
+		})
+	}
+	return l
+}
+
+// Load builds the configuration from defaults, the config file, the
+// environment and flags, and validates the result
+func (l *Loader) Load() (*Config, error) {
+	cfg := Default()
+
+	path := l.path
+	if path == "" {
+		path = os.Getenv(ConfigFileEnv)
+	}
+	if path != "" {
+		if err := loadFile(cfg, path); err != nil {
+			return nil, err
+		}
+	}
+
+	bySetting := make(map[string]setting)
+	for _, s := range settings(cfg) {
+		bySetting[s.key] = s
+
+		value, ok, err := lookupEnv(s.env)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			if err := s.set(value); err != nil {
+				return nil, fmt.Errorf("%s: %w", s.env, err)
+			}
+		}
+	}
+
+	for _, f := range l.flags {
+		if err := bySetting[f.key].set(f.value); err != nil {
+			return nil, fmt.Errorf("--%s: %w", f.key, err)
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return cfg, nil
+}
+
+// Load is a shorthand for loading without flags
+func Load() (*Config, error) {
+	return NewLoader(nil).Load()
+}
+
+func loadFile(cfg *Config, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// lookupEnv reads an environment variable, or the file named by its _FILE
+// variant, which is how Kubernetes Secrets mounted as files are passed in.
+// Empty variables count as unset.
+func lookupEnv(name string) (string, bool, error) {
+	value := os.Getenv(name)
+	path := os.Getenv(name + "_FILE")
+
+	switch {
+	case value != "" && path != "":
+		return "", false, fmt.Errorf("only one of %s and %s_FILE may be set", name, name)
+	case path != "":
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return "", false, fmt.Errorf("%s_FILE: %w", name, err)
+		}
+		return strings.TrimRight(string(contents), "\r\n"), true, nil
+	default:
+		return value, value != "", nil
+	}
+}
+
+// Redacted returns the configuration by section and key with secrets masked
+// and durations spelled out, for showing to operators
+func (c *Config) Redacted() map[string]map[string]interface{} {
+	dump := make(map[string]map[string]interface{})
+	for _, s := range settings(c) {
+		section, key, _ := strings.Cut(s.key, ".")
+		if dump[section] == nil {
+			dump[section] = make(map[string]interface{})
+		}
+
+		var value interface{} = s.value.Interface()
+		switch {
+		case s.secret:
+			if !s.value.IsZero() {
+				value = redacted
+			}
+		case s.value.Type() == durationType:
+			value = s.value.Interface().(time.Duration).String()
+		}
+		dump[section][key] = value
+	}
+	return dump
+}
+
+// setting is a single field of Config, addressed through its struct tags
+type setting struct {
+	key    string
+	env    string
+	secret bool
+	value  reflect.Value
+}
+
+// settings lists the fields of every section of cfg
+func settings(cfg *Config) []setting {
+	var all []setting
+
+	root := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < root.NumField(); i++ {
+		section := root.Field(i)
+		sectionName := root.Type().Field(i).Tag.Get("yaml")
+
+		for j := 0; j < section.NumField(); j++ {
+			field := section.Type().Field(j)
+			all = append(all, setting{
+				key:    sectionName + "." + field.Tag.Get("yaml"),
+				env:    field.Tag.Get("env"),
+				secret: field.Tag.Get("secret") == "true",
+				value:  section.Field(j),
+			})
+		}
+	}
+	return all
+}
+
+func (s setting) set(raw string) error {
+	if s.value.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a duration", raw)
+		}
+		s.value.SetInt(int64(d))
+		return nil
+	}
+
+	switch s.value.Kind() {
+	case reflect.String:
+		s.value.SetString(raw)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", raw)
+		}
+		s.value.SetInt(int64(n))
+	case reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a non-negative integer", raw)
+		}
+		s.value.SetUint(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", raw)
+		}
+		s.value.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", raw)
+		}
+		s.value.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported setting type %s", s.value.Type())
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// clearEnv unsets every variable the loader reads, for the duration of the
+// test
+func clearEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv(ConfigFileEnv, "")
+	for _, s := range settings(Default()) {
+		t.Setenv(s.env, "")
+		t.Setenv(s.env+"_FILE", "")
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// load loads with args parsed as command line flags
+func load(t *testing.T, args ...string) (*Config, error) {
+	t.Helper()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	loader := NewLoader(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return loader.Load()
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	const yaml = "server:\n  port: \"9001\"\ndatabase:\n  password: from-yaml\n  max_open_conns: 50\n"
+
+	tests := []struct {
+		name     string
+		yaml     bool
+		env      map[string]string
+		files    map[string]string
+		args     []string
+		port     string
+		password string
+	}{
+		{"defaults", false, nil, nil, nil, "8080", "postgres"},
+		{"file over defaults", true, nil, nil, nil, "9001", "from-yaml"},
+		{"env over file", true,
+			map[string]string{"PORT": "9002", "DB_PASSWORD": "from-env"}, nil, nil,
+			"9002", "from-env"},
+		{"_FILE over file", true,
+			nil, map[string]string{"DB_PASSWORD": "from-secret\n"}, nil,
+			"9001", "from-secret"},
+		{"flags over env", true,
+			map[string]string{"PORT": "9002"}, map[string]string{"DB_PASSWORD": "from-secret"},
+			[]string{"--server.port=9003", "--database.password=from-flag"},
+			"9003", "from-flag"},
+		{"empty env is unset", true,
+			map[string]string{"PORT": ""}, nil, nil,
+			"9001", "from-yaml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			if tt.yaml {
+				t.Setenv(ConfigFileEnv, writeFile(t, "config.yaml", yaml))
+			}
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			for name, content := range tt.files {
+				t.Setenv(name+"_FILE", writeFile(t, name, content))
+			}
+
+			cfg, err := load(t, tt.args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Server.Port != tt.port || cfg.Database.Password != tt.password {
+				t.Errorf("port %q, password %q; want %q, %q", cfg.Server.Port, cfg.Database.Password, tt.port, tt.password)
+			}
+			// Settings no source mentions keep their default
+			if want := Default().Server.RequestTimeout; cfg.Server.RequestTimeout != want {
+				t.Errorf("request timeout = %s, want the default %s", cfg.Server.RequestTimeout, want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFlagOverridesEnv(t *testing.T) {
+	clearEnv(t)
+	t.Setenv(ConfigFileEnv, writeFile(t, "env.yaml", "server:\n  port: \"9001\"\n"))
+	path := writeFile(t, "flag.yaml", "server:\n  port: \"9004\"\n")
+
+	cfg, err := load(t, "--config", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.Port != "9004" {
+		t.Errorf("port = %q, want 9004 from the --config file", cfg.Server.Port)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		yaml  string
+		env   map[string]string
+		files map[string]string
+		args  []string
+		want  []string
+	}{
+		{"unknown key", "server:\n  prot: \"9001\"\n", nil, nil, nil,
+			[]string{"failed to parse config file", "field prot not found"}},
+		{"env and _FILE", "", map[string]string{"DB_PASSWORD": "a"}, map[string]string{"DB_PASSWORD": "b"}, nil,
+			[]string{"only one of DB_PASSWORD and DB_PASSWORD_FILE may be set"}},
+		{"bad duration", "", map[string]string{"REQUEST_TIMEOUT": "soon"}, nil, nil,
+			[]string{`REQUEST_TIMEOUT: "soon" is not a duration`}},
+		{"bad flag", "", nil, nil, []string{"--database.max_open_conns=many"},
+			[]string{`--database.max_open_conns: "many" is not an integer`}},
+		{"every invalid setting", "", map[string]string{"LOG_LEVEL": "loud", "DB_MAX_IDLE_CONNS": "500"}, nil, nil,
+			[]string{"invalid configuration", `log.level: "loud" is not one of debug, info, warn, error`, "database.max_idle_conns"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			if tt.yaml != "" {
+				t.Setenv(ConfigFileEnv, writeFile(t, "config.yaml", tt.yaml))
+			}
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			for name, content := range tt.files {
+				t.Setenv(name+"_FILE", writeFile(t, name, content))
+			}
+
+			_, err := load(t, tt.args...)
+			if err == nil {
+				t.Fatal("loaded without an error")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not mention %q", err, want)
+				}
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		clearEnv(t)
+		if _, err := load(t, "--config", filepath.Join(t.TempDir(), "missing.yaml")); err == nil ||
+			!strings.Contains(err.Error(), "failed to read config file") {
+			t.Errorf("got error %v, want a read failure", err)
+		}
+	})
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := Default()
+	dump := cfg.Redacted()
+
+	if got := dump["database"]["password"]; got != redacted {
+		t.Errorf("database.password = %v, want it masked", got)
+	}
+	// An unset secret shows that it is unset
+	if got := dump["admin"]["token"]; got != "" {
+		t.Errorf("admin.token = %v, want empty", got)
+	}
+	if got := dump["server"]["request_timeout"]; got != "10s" {
+		t.Errorf("server.request_timeout = %v, want 10s", got)
+	}
+	if got := dump["database"]["max_open_conns"]; got != 100 {
+		t.Errorf("database.max_open_conns = %v, want 100", got)
+	}
+
+	cfg.Admin.Token = "s3cret"
+	if got := cfg.Redacted()["admin"]["token"]; got != redacted {
+		t.Errorf("admin.token = %v, want it masked", got)
+	}
+	if cfg.Admin.Token != "s3cret" || cfg.Database.Password != "postgres" {
+		t.Error("Redacted changed the configuration")
+	}
+}
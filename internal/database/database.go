@@ -1,62 +1,29 @@
 package database
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
-	"log"
-	"os"
-	"time"
+	"log/slog"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 
-	"postService/internal/models"
+	"postService/internal/config"
+	"postService/internal/logging"
 )
 
 type Database struct {
 	DB *gorm.DB
 }
 
-type Config struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	DBName   string
-	SSLMode  string
-}
-
-func NewConfig() *Config {
-	return &Config{
-		Host:     getEnv("DB_HOST", "localhost"),
-		Port:     getEnv("DB_PORT", "5432"),
-		User:     getEnv("DB_USER", "postgres"),
-		Password: getEnv("DB_PASSWORD", "postgres"),
-		DBName:   getEnv("DB_NAME", "postservice"),
-		SSLMode:  getEnv("DB_SSLMODE", "disable"),
-	}
-}
-
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
-
-func (c *Config) DatabaseURL() string {
-	return fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		c.Host, c.Port, c.User, c.Password, c.DBName, c.SSLMode,
-	)
-}
-
-func NewDatabase(config *Config) (*Database, error) {
+func NewDatabase(cfg config.DatabaseConfig) (*Database, error) {
 	gormConfig := &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+		Logger: logging.NewGormLogger(cfg.SlowQueryThreshold),
 	}
 
-	db, err := gorm.Open(postgres.Open(config.DatabaseURL()), gormConfig)
+	db, err := gorm.Open(postgres.Open(cfg.DatabaseURL()), gormConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
@@ -68,26 +35,38 @@ func NewDatabase(config *Config) (*Database, error) {
 	}
 
 	// Set connection pool settings
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
-	sqlDB.SetConnMaxLifetime(time.Hour)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
 
-	log.Println("Connected to PostgreSQL database")
+	slog.Info("Connected to PostgreSQL database", "host", cfg.Host, "database", cfg.Name)
 
 	return &Database{DB: db}, nil
 }
 
-func (d *Database) Migrate() error {
-	log.Println("Running database migrations...")
+// Migrator returns a migrator for the embedded SQL migrations
+func (d *Database) Migrator() (*Migrator, error) {
+	sqlDB, err := d.DB.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get underlying sql.DB: %w", err)
+	}
+	return NewMigrator(sqlDB)
+}
+
+// Migrate applies every pending migration. Replicas starting together take
+// turns, and the ones that go second find nothing left to do.
+func (d *Database) Migrate(ctx context.Context) error {
+	slog.InfoContext(ctx, "Running database migrations")
 	
-	err := d.DB.AutoMigrate(
-		&models.Post{},
-	)
+	migrator, err := d.Migrator()
 	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 
-	log.Println("Database migrations completed successfully")
+	slog.InfoContext(ctx, "Database migrations completed successfully")
 	return nil
 }
 
@@ -99,10 +78,82 @@ func (d *Database) Close() error {
 	return sqlDB.Close()
 }
 
-func (d *Database) Health() error {
+func (d *Database) Health(ctx context.Context) error {
 	sqlDB, err := d.DB.DB()
 	if err != nil {
 		return err
 	}
-	return sqlDB.Ping()
+	return sqlDB.PingContext(ctx)
+}
+
+// Stats reports the usage of the connection pool
+func (d *Database) Stats() (sql.DBStats, error) {
+	sqlDB, err := d.DB.DB()
+	if err != nil {
+		return sql.DBStats{}, err
+	}
+	return sqlDB.Stats(), nil
+}
+
+// ServerInfo describes the Postgres server the pool is connected to
+type ServerInfo struct {
+	Version string
+	// InRecovery is true on a standby, which only serves reads
+	InRecovery bool
+}
+
+// ReplicationRole names the server's role: primary or replica
+func (i ServerInfo) ReplicationRole() string {
+	if i.InRecovery {
+		return "replica"
+	}
+	return "primary"
+}
+
+func (d *Database) ServerInfo(ctx context.Context) (ServerInfo, error) {
+	var info ServerInfo
+	sqlDB, err := d.DB.DB()
+	if err != nil {
+		return info, err
+	}
+	err = sqlDB.QueryRowContext(ctx, "SELECT current_setting('server_version'), pg_is_in_recovery()").
+		Scan(&info.Version, &info.InRecovery)
+	return info, err
+}
+
+// probeLockTimeout bounds how long the write probe waits for a lock on posts
+const probeLockTimeout = "1s"
+
+// Probe exercises the paths requests take rather than just the connection:
+// a read from posts and, unless readOnly, a write to posts in a transaction
+// that is rolled back. The write matches no rows, but still needs the table
+// to accept writes, so it fails on a read-only server or a locked table.
+func (d *Database) Probe(ctx context.Context, readOnly bool) error {
+	sqlDB, err := d.DB.DB()
+	if err != nil {
+		return err
+	}
+
+	var one int
+	err = sqlDB.QueryRowContext(ctx, "SELECT 1 FROM posts LIMIT 1").Scan(&one)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("read: %w", err)
+	}
+	if readOnly {
+		return nil
+	}
+
+	tx, err := sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "SET LOCAL lock_timeout = '"+probeLockTimeout+"'"); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE posts SET updated_at = updated_at WHERE false"); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	return nil
 }
\ No newline at end of file
@@ -0,0 +1,86 @@
+-- Baseline schema. Every statement is IF NOT EXISTS so databases created by
+-- AutoMigrate before versioned migrations adopt it: their posts table only
+-- has id, title, content, author, created_at and updated_at, so the columns
+-- added since are added here before they are indexed.
+
+CREATE TABLE IF NOT EXISTS posts (
+    id         uuid PRIMARY KEY,
+    title      text NOT NULL,
+    slug       varchar(100),
+    content    text NOT NULL,
+    author     text NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    version    bigint NOT NULL DEFAULT 1,
+    status     varchar(20) NOT NULL DEFAULT 'published',
+    publish_at timestamptz,
+    deleted_at timestamptz
+);
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS slug varchar(100);
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT 1;
+-- Posts that predate the lifecycle were all visible, so they are published
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS status varchar(20) NOT NULL DEFAULT 'published';
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS publish_at timestamptz;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS deleted_at timestamptz;
+
+-- Existing posts get a NULL slug, which a unique index does not compare, so
+-- the index holds until BackfillSlugs gives them one at startup
+CREATE UNIQUE INDEX IF NOT EXISTS idx_posts_slug ON posts (slug);
+CREATE INDEX IF NOT EXISTS idx_posts_author ON posts (author);
+CREATE INDEX IF NOT EXISTS idx_posts_created_at_id ON posts (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_posts_status ON posts (status);
+CREATE INDEX IF NOT EXISTS idx_posts_publish_at ON posts (publish_at);
+CREATE INDEX IF NOT EXISTS idx_posts_deleted_at ON posts (deleted_at);
+
+CREATE TABLE IF NOT EXISTS post_slugs (
+    slug       varchar(100) PRIMARY KEY,
+    post_id    uuid NOT NULL,
+    created_at timestamptz,
+    CONSTRAINT fk_post_slugs_post FOREIGN KEY (post_id) REFERENCES posts (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_post_slugs_post_id ON post_slugs (post_id);
+
+CREATE TABLE IF NOT EXISTS tags (
+    id   bigserial PRIMARY KEY,
+    name varchar(50) NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_name ON tags (name);
+
+CREATE TABLE IF NOT EXISTS post_tags (
+    post_id uuid,
+    tag_id  bigint,
+    PRIMARY KEY (post_id, tag_id),
+    CONSTRAINT fk_post_tags_post FOREIGN KEY (post_id) REFERENCES posts (id) ON DELETE CASCADE,
+    CONSTRAINT fk_post_tags_tag FOREIGN KEY (tag_id) REFERENCES tags (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS post_revisions (
+    id         bigserial PRIMARY KEY,
+    post_id    uuid NOT NULL,
+    revision   bigint NOT NULL,
+    title      text NOT NULL,
+    content    text NOT NULL,
+    author     text NOT NULL,
+    editor     text NOT NULL,
+    created_at timestamptz,
+    CONSTRAINT fk_post_revisions_post FOREIGN KEY (post_id) REFERENCES posts (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_post_revisions_post_revision ON post_revisions (post_id, revision);
+
+CREATE TABLE IF NOT EXISTS comments (
+    id         uuid PRIMARY KEY,
+    post_id    uuid NOT NULL,
+    parent_id  uuid,
+    root_id    uuid,
+    depth      bigint NOT NULL DEFAULT 0,
+    author     text NOT NULL,
+    body       text NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    CONSTRAINT fk_comments_post FOREIGN KEY (post_id) REFERENCES posts (id) ON DELETE CASCADE,
+    CONSTRAINT fk_comments_parent FOREIGN KEY (parent_id) REFERENCES comments (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_comments_post_thread ON comments (post_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_comments_parent_id ON comments (parent_id);
+CREATE INDEX IF NOT EXISTS idx_comments_root_id ON comments (root_id);
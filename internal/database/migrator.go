@@ -0,0 +1,317 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockID is the Postgres advisory lock key that serialises
+// migrations across replicas starting at the same time
+const migrationLockID int64 = 0x706f7374_6d696772 // "postmigr"
+
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is one numbered schema change with the SQL to apply and revert it
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus reports whether a migration has been applied, and when
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+// MigrationStep is a migration the migrator runs, in the direction it runs it
+type MigrationStep struct {
+	Migration
+	Revert bool
+}
+
+func (s MigrationStep) String() string {
+	direction := "up"
+	if s.Revert {
+		direction = "down"
+	}
+	return fmt.Sprintf("%04d_%s (%s)", s.Version, s.Name, direction)
+}
+
+// SQL returns the statements the step executes
+func (s MigrationStep) SQL() string {
+	if s.Revert {
+		return s.Down
+	}
+	return s.Up
+}
+
+// Migrator applies the embedded SQL migrations and records them in the
+// schema_migrations table. Each migration runs in its own transaction.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+	// DryRun makes the migrator report the steps it would run without
+	// running them or creating schema_migrations
+	DryRun bool
+}
+
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	migrations, err := loadMigrations(migrationFiles)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Latest returns the highest migration version known to this binary
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Status lists every known migration and when it was applied
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	applied, err := appliedMigrations(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if appliedAt, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Up applies every pending migration
+func (m *Migrator) Up(ctx context.Context) ([]MigrationStep, error) {
+	return m.run(ctx, func(map[int]time.Time) int { return m.Latest() })
+}
+
+// Down reverts the most recently applied migration
+func (m *Migrator) Down(ctx context.Context) ([]MigrationStep, error) {
+	return m.run(ctx, func(applied map[int]time.Time) int {
+		versions := sortedVersions(applied)
+		if len(versions) < 2 {
+			return 0
+		}
+		return versions[len(versions)-2]
+	})
+}
+
+// To applies or reverts migrations until version is the latest one applied
+func (m *Migrator) To(ctx context.Context, version int) ([]MigrationStep, error) {
+	if version != 0 && m.find(version) == nil {
+		return nil, fmt.Errorf("unknown migration version %d", version)
+	}
+	return m.run(ctx, func(map[int]time.Time) int { return version })
+}
+
+// run holds the advisory lock while it works out and runs the steps towards
+// the target version, so concurrent runs see each other's changes
+func (m *Migrator) run(ctx context.Context, target func(applied map[int]time.Time) int) ([]MigrationStep, error) {
+	// Session advisory locks belong to a connection, so everything runs on one
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return nil, fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID)
+
+	if !m.DryRun {
+		if _, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    bigint PRIMARY KEY,
+			name       text NOT NULL,
+			applied_at timestamptz NOT NULL DEFAULT now()
+		)`); err != nil {
+			return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+		}
+	}
+
+	applied, err := appliedMigrations(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	steps, err := m.plan(applied, target(applied))
+	if err != nil || m.DryRun {
+		return steps, err
+	}
+
+	for i, step := range steps {
+		if err := applyStep(ctx, conn, step); err != nil {
+			return steps[:i], fmt.Errorf("migration %s failed: %w", step, err)
+		}
+		slog.InfoContext(ctx, "Applied migration", "migration", step.String())
+	}
+	return steps, nil
+}
+
+// plan lists the steps that take the applied set to target: pending
+// migrations up to target in ascending order, then applied migrations above
+// target in descending order
+func (m *Migrator) plan(applied map[int]time.Time, target int) ([]MigrationStep, error) {
+	var steps []MigrationStep
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok && migration.Version <= target {
+			steps = append(steps, MigrationStep{Migration: migration})
+		}
+	}
+
+	versions := sortedVersions(applied)
+	for i := len(versions) - 1; i >= 0 && versions[i] > target; i-- {
+		migration := m.find(versions[i])
+		if migration == nil {
+			return nil, fmt.Errorf("database has migration %d applied, which this binary does not know", versions[i])
+		}
+		steps = append(steps, MigrationStep{Migration: *migration, Revert: true})
+	}
+	return steps, nil
+}
+
+func (m *Migrator) find(version int) *Migration {
+	i := sort.Search(len(m.migrations), func(i int) bool { return m.migrations[i].Version >= version })
+	if i < len(m.migrations) && m.migrations[i].Version == version {
+		return &m.migrations[i]
+	}
+	return nil
+}
+
+func applyStep(ctx context.Context, conn *sql.Conn, step MigrationStep) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, step.SQL()); err != nil {
+		return err
+	}
+	if step.Revert {
+		_, err = tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = $1", step.Version)
+	} else {
+		_, err = tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", step.Version, step.Name)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// appliedMigrations returns the applied versions and when they were applied,
+// treating a missing schema_migrations table as nothing applied
+func appliedMigrations(ctx context.Context, conn *sql.Conn) (map[int]time.Time, error) {
+	applied := make(map[int]time.Time)
+
+	var exists bool
+	if err := conn.QueryRowContext(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return applied, nil
+	}
+
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+func sortedVersions(applied map[int]time.Time) []int {
+	versions := make([]int, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+	return versions
+}
+
+// loadMigrations pairs up the NNNN_name.up.sql and NNNN_name.down.sql files
+// under migrations/ and orders them by version
+func loadMigrations(files fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(files, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("unexpected migration file %q", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		if version <= 0 {
+			return nil, fmt.Errorf("migration file %q: version must be positive", entry.Name())
+		}
+
+		body, err := fs.ReadFile(files, path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has files named %q and %q", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(body)
+		} else {
+			migration.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s needs both an up and a down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
@@ -0,0 +1,126 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"postService/internal/repository"
+)
+
+// testDB connects to the Postgres named by TEST_DATABASE_URL, in a schema of
+// its own that is dropped when the test ends. Tests using it are skipped when
+// the variable is not set.
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	admin, err := sql.Open("pgx", url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Close() })
+
+	schema := fmt.Sprintf("migrator_test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Exec("DROP SCHEMA " + schema + " CASCADE") })
+
+	cfg, err := pgx.ParseConfig(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.RuntimeParams["search_path"] = schema
+	db := stdlib.OpenDB(*cfg)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// baselinePost is the post model AutoMigrate created tables from before
+// versioned migrations
+type baselinePost struct {
+	ID        string    `gorm:"type:uuid;primary_key"`
+	Title     string    `gorm:"not null"`
+	Content   string    `gorm:"not null"`
+	Author    string    `gorm:"not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+func (baselinePost) TableName() string { return "posts" }
+
+func TestMigrateAdoptsAutoMigratedDatabase(t *testing.T) {
+	ctx := context.Background()
+	sqlDB := testDB(t)
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.AutoMigrate(&baselinePost{}); err != nil {
+		t.Fatal(err)
+	}
+	existing := []baselinePost{
+		{ID: "7b0f6a4e-0d1c-4f0e-9a51-1f2d3c4b5a61", Title: "Hello World", Content: "first", Author: "ann"},
+		{ID: "7b0f6a4e-0d1c-4f0e-9a51-1f2d3c4b5a62", Title: "Hello World", Content: "second", Author: "bob"},
+	}
+	if err := db.Create(&existing).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	migrator, err := NewMigrator(sqlDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("migrating a baseline database: %v", err)
+	}
+	if err := repository.BackfillSlugs(ctx, db); err != nil {
+		t.Fatalf("backfilling slugs: %v", err)
+	}
+
+	rows, err := sqlDB.QueryContext(ctx, "SELECT slug, version, status, publish_at IS NULL, deleted_at IS NULL FROM posts ORDER BY slug")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var slugs []string
+	for rows.Next() {
+		var slug, status string
+		var version int
+		var noPublishAt, notDeleted bool
+		if err := rows.Scan(&slug, &version, &status, &noPublishAt, &notDeleted); err != nil {
+			t.Fatal(err)
+		}
+		if version != 1 || status != "published" || !noPublishAt || !notDeleted {
+			t.Errorf("post %q: got version %d, status %q, publish_at null %v, deleted_at null %v; want 1, published, true, true",
+				slug, version, status, noPublishAt, notDeleted)
+		}
+		slugs = append(slugs, slug)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"hello-world", "hello-world-2"}; fmt.Sprint(slugs) != fmt.Sprint(want) {
+		t.Errorf("slugs = %v, want %v", slugs, want)
+	}
+
+	// The slug index is in place once the backfill has run
+	_, err = sqlDB.ExecContext(ctx, "UPDATE posts SET slug = 'hello-world' WHERE content = 'second'")
+	if err == nil {
+		t.Error("duplicate slug was accepted")
+	}
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"postService/internal/models"
+	"postService/internal/service"
+)
+
+type CommentHandler struct {
+	service service.CommentService
+}
+
+func NewCommentHandler(service service.CommentService) *CommentHandler {
+	return &CommentHandler{service: service}
+}
+
+// CreateComment godoc
+// @Summary Comment on a post
+// @Description Add a comment to a published post. Set parent_id to reply to another comment on the same post.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path string true "Post ID"
+// @Param comment body models.CreateCommentRequest true "Comment data"
+// @Success 201 {object} models.Comment
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Router /posts/{id}/comments [post]
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+	var req models.CreateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(validationError(err))
+		return
+	}
+
+	comment, err := h.service.CreateComment(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+// ListComments godoc
+// @Summary List comments on a post
+// @Description Get a page of top-level comments on a post, oldest first, each with its replies nested underneath. Deleted comments are kept as tombstones so their replies stay in place.
+// @Tags comments
+// @Produce json
+// @Param id path string true "Post ID"
+// @Param limit query int false "Top-level comments per page (default 20, max 100)"
+// @Param cursor query string false "Opaque cursor returned as next_cursor by the previous page"
+// @Success 200 {object} models.CommentPage
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Router /posts/{id}/comments [get]
+func (h *CommentHandler) ListComments(c *gin.Context) {
+	query, err := parseCommentQuery(c)
+	if err != nil {
+		c.Error(validationError(err))
+		return
+	}
+
+	page, err := h.service.ListComments(c.Request.Context(), query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// GetComment godoc
+// @Summary Get a comment
+// @Description Get a single comment on a post, without its replies
+// @Tags comments
+// @Produce json
+// @Param id path string true "Post ID"
+// @Param comment_id path string true "Comment ID"
+// @Success 200 {object} models.Comment
+// @Failure 404 {object} models.Problem
+// @Router /posts/{id}/comments/{comment_id} [get]
+func (h *CommentHandler) GetComment(c *gin.Context) {
+	comment, err := h.service.GetComment(c.Request.Context(), c.Param("id"), c.Param("comment_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// UpdateComment godoc
+// @Summary Edit a comment
+// @Description Replace the body of a comment. Deleted comments cannot be edited.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path string true "Post ID"
+// @Param comment_id path string true "Comment ID"
+// @Param comment body models.UpdateCommentRequest true "New comment body"
+// @Success 200 {object} models.Comment
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Router /posts/{id}/comments/{comment_id} [put]
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	var req models.UpdateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(validationError(err))
+		return
+	}
+
+	comment, err := h.service.UpdateComment(c.Request.Context(), c.Param("id"), c.Param("comment_id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// DeleteComment godoc
+// @Summary Delete a comment
+// @Description Replace a comment with a tombstone. Its replies stay in the thread.
+// @Tags comments
+// @Param id path string true "Post ID"
+// @Param comment_id path string true "Comment ID"
+// @Success 204
+// @Failure 404 {object} models.Problem
+// @Router /posts/{id}/comments/{comment_id} [delete]
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	if err := h.service.DeleteComment(c.Request.Context(), c.Param("id"), c.Param("comment_id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// parseCommentQuery reads the pagination parameters of a comment listing
+func parseCommentQuery(c *gin.Context) (models.CommentQuery, error) {
+	query := models.CommentQuery{PostID: c.Param("id")}
+
+	limit, err := parseLimitParam(c)
+	if err != nil {
+		return query, err
+	}
+	if query.Page, err = models.NewPageRequest(limit, c.Query("cursor")); err != nil {
+		return query, err
+	}
+
+	_, err = query.CursorTime()
+	return query, err
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"postService/internal/config"
+)
+
+type ConfigHandler struct {
+	config *config.Config
+}
+
+func NewConfigHandler(cfg *config.Config) *ConfigHandler {
+	return &ConfigHandler{config: cfg}
+}
+
+// GetConfig godoc
+// @Summary Show the effective configuration
+// @Description Show the configuration the server is running with, by section and key. Secrets are redacted. Requires the admin token.
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Success 200 {object} map[string]map[string]interface{}
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Router /admin/config [get]
+func (h *ConfigHandler) GetConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, h.config.Redacted())
+}
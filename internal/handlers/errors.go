@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"postService/internal/models"
+	"postService/internal/service"
+)
+
+func init() {
+	// Report binding failures under the JSON names clients actually send
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+	}
+}
+
+// validationError turns a request that failed to bind or validate into an
+// error of the validation kind, listing the offending fields when known
+func validationError(err error) error {
+	if errors.Is(err, service.ErrValidation) {
+		return err
+	}
+
+	var fieldErrs validator.ValidationErrors
+	if errors.As(err, &fieldErrs) {
+		verr := &service.ValidationError{}
+		for _, fe := range fieldErrs {
+			verr.Fields = append(verr.Fields, models.FieldError{Field: fe.Field(), Message: validationMessage(fe)})
+		}
+		return verr
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return service.NewValidationError(typeErr.Field, "must be a JSON "+jsonType(typeErr.Type))
+	}
+
+	return &service.DomainError{Kind: service.ErrValidation, Message: err.Error()}
+}
+
+// bindOptionalJSON binds the request body into obj unless there is none,
+// leaving obj as it is. Chunked bodies have no Content-Length, so an empty
+// body only shows up as io.EOF once decoding starts.
+func bindOptionalJSON(c *gin.Context, obj any) error {
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return nil
+	}
+	if err := c.ShouldBindJSON(obj); err != nil && !errors.Is(err, io.EOF) {
+		return validationError(err)
+	}
+	return nil
+}
+
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	default:
+		return "failed the " + fe.Tag() + " check"
+	}
+}
+
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	case reflect.Bool:
+		return "boolean"
+	default:
+		return "number"
+	}
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"postService/internal/models"
+	"postService/internal/service"
+)
+
+// errInvalidIfMatch fails the precondition like a stale ETag would, since no
+// version can match it
+var errInvalidIfMatch = &service.DomainError{
+	Kind:    service.ErrVersionMismatch,
+	Message: "If-Match must be \"*\" or a single ETag returned by this API",
+}
+
+// setETag exposes the post version as its entity tag
+func setETag(c *gin.Context, post *models.Post) {
+	c.Header("ETag", strconv.Quote(strconv.Itoa(post.Version)))
+}
+
+// parseIfMatch returns the post version required by the If-Match header.
+// It returns 0 when the header is absent or "*", meaning any version matches.
+func parseIfMatch(c *gin.Context) (int, error) {
+	value := strings.TrimSpace(c.GetHeader("If-Match"))
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+
+	// Weak validators are accepted since versions are never reused
+	value = strings.TrimPrefix(value, "W/")
+	unquoted, err := strconv.Unquote(value)
+	if err != nil {
+		return 0, errInvalidIfMatch
+	}
+	version, err := strconv.Atoi(unquoted)
+	if err != nil || version < 1 {
+		return 0, errInvalidIfMatch
+	}
+	return version, nil
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"postService/internal/models"
+)
+
+// nagiosStates maps health statuses onto Nagios plugin states
+var nagiosStates = map[models.HealthStatus]string{
+	models.HealthStatusHealthy:   "OK",
+	models.HealthStatusDegraded:  "WARNING",
+	models.HealthStatusUnhealthy: "CRITICAL",
+}
+
+// nagiosLine summarises a health report the way a Nagios plugin does: the
+// state, the components that are not healthy and why, and the check
+// durations as performance data, such as
+//
+//	WARNING - database degraded (High connection usage detected) | database=12ms memory=0ms goroutines=0ms
+func nagiosLine(health *models.HealthResponse) string {
+	state, ok := nagiosStates[health.Status]
+	if !ok {
+		state = "UNKNOWN"
+	}
+
+	var problems, perfdata []string
+	for _, component := range health.Components {
+		perfdata = append(perfdata, fmt.Sprintf("%s=%dms", component.Name, component.ResponseTime))
+		if component.Status == models.HealthStatusHealthy {
+			continue
+		}
+
+		reason := component.Error
+		if reason == "" {
+			reason = component.Message
+		}
+		problems = append(problems, fmt.Sprintf("%s %s (%s)", component.Name, component.Status, reason))
+	}
+
+	summary := fmt.Sprintf("all %d components healthy", len(health.Components))
+	if len(problems) > 0 {
+		summary = strings.Join(problems, ", ")
+	}
+	// The text must stay on one line and must not contain the perfdata separator
+	summary = strings.NewReplacer("\n", " ", "\r", " ", "|", "/").Replace(summary)
+
+	return fmt.Sprintf("%s - %s | %s", state, summary, strings.Join(perfdata, " "))
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"postService/internal/models"
+	"postService/internal/service"
+)
+
+func TestNagiosLine(t *testing.T) {
+	database := models.ComponentHealth{Name: "database", Status: models.HealthStatusHealthy, ResponseTime: 12}
+	memory := models.ComponentHealth{Name: "memory", Status: models.HealthStatusHealthy}
+
+	tests := []struct {
+		name   string
+		health models.HealthResponse
+		want   string
+	}{
+		{"ok", models.HealthResponse{
+			Status:     models.HealthStatusHealthy,
+			Components: []models.ComponentHealth{database, memory},
+		}, "OK - all 2 components healthy | database=12ms memory=0ms"},
+		{"warning uses the message", models.HealthResponse{
+			Status: models.HealthStatusDegraded,
+			Components: []models.ComponentHealth{
+				{Name: "database", Status: models.HealthStatusDegraded, ResponseTime: 12, Message: "High connection usage detected"},
+				memory,
+			},
+		}, "WARNING - database degraded (High connection usage detected) | database=12ms memory=0ms"},
+		{"critical prefers the error", models.HealthResponse{
+			Status: models.HealthStatusUnhealthy,
+			Components: []models.ComponentHealth{
+				{Name: "database", Status: models.HealthStatusUnhealthy, ResponseTime: 5000, Message: "ignored", Error: "Check did not finish within 5s"},
+				{Name: "memory", Status: models.HealthStatusDegraded, Message: "High memory usage detected"},
+			},
+		}, "CRITICAL - database unhealthy (Check did not finish within 5s), memory degraded (High memory usage detected) | database=5000ms memory=0ms"},
+		{"unknown status", models.HealthResponse{
+			Status:     "",
+			Components: []models.ComponentHealth{database},
+		}, "UNKNOWN - all 1 components healthy | database=12ms"},
+		{"newlines and pipes are escaped", models.HealthResponse{
+			Status: models.HealthStatusUnhealthy,
+			Components: []models.ComponentHealth{
+				{Name: "database", Status: models.HealthStatusUnhealthy, Error: "dial tcp: refused\r\nretry | later"},
+			},
+		}, "CRITICAL - database unhealthy (dial tcp: refused  retry / later) | database=0ms"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nagiosLine(&tt.health); got != tt.want {
+				t.Errorf("got  %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetHealthFormats(t *testing.T) {
+	router := newHealthRouter(service.NewStartupTracker(),
+		stubChecker{"database", true, models.HealthStatusUnhealthy})
+
+	tests := []struct {
+		path, accept string
+		status       int
+		contentType  string
+		prefix       string
+	}{
+		{"/health", "", http.StatusServiceUnavailable, "application/json", `{"status":"unhealthy"`},
+		{"/health", "text/plain", http.StatusServiceUnavailable, "text/plain", "CRITICAL - database unhealthy"},
+		{"/health?format=text", "", http.StatusServiceUnavailable, "text/plain", "CRITICAL - database unhealthy"},
+		{"/health?format=json", "text/plain", http.StatusServiceUnavailable, "application/json", `{"status":"unhealthy"`},
+		// Scrapes succeed whatever the health; the status is in the gauges
+		{"/health?format=prometheus", "", http.StatusOK, "text/plain", "# HELP health_component_response_ms"},
+		{"/health?format=xml", "", http.StatusBadRequest, "application/problem+json", "{"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path+" "+tt.accept, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := serve(router, req)
+
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+			if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, tt.contentType) {
+				t.Errorf("content type = %q, want %s", got, tt.contentType)
+			}
+			if !strings.HasPrefix(w.Body.String(), tt.prefix) {
+				t.Errorf("body = %q, want it to start with %q", w.Body.String(), tt.prefix)
+			}
+		})
+	}
+
+	w := get(router, "/health?format=prometheus")
+	if !strings.Contains(w.Body.String(), `health_component_status{component="database"} 0`) {
+		t.Errorf("prometheus output lacks the database status:\n%s", w.Body.String())
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"postService/internal/metrics"
 	"postService/internal/models"
 	"postService/internal/service"
 )
@@ -20,14 +21,36 @@ func NewHealthHandler(healthService service.HealthService) *HealthHandler {
 
 // GetHealth godoc
 // @Summary Get comprehensive health status
-// @Description Get detailed health information including all component statuses
+// @Description Get detailed health information including all component statuses. With Accept: text/plain the report is a Nagios-style line, and with format=prometheus it is a set of gauges in the Prometheus text format, always served with 200.
 // @Tags health
 // @Produce json
+// @Produce plain
+// @Param format query string false "Output format instead of content negotiation" Enums(json, text, prometheus)
 // @Success 200 {object} models.HealthResponse "Service is healthy"
 // @Success 503 {object} models.HealthResponse "Service is degraded or unhealthy"
+// @Failure 400 {object} models.Problem "Unknown format"
 // @Router /health [get]
 func (h *HealthHandler) GetHealth(c *gin.Context) {
-	health := h.healthService.GetHealth()
+	format := c.Query("format")
+	switch format {
+	case "":
+		format = "json"
+		if c.NegotiateFormat(gin.MIMEJSON, gin.MIMEPlain) == gin.MIMEPlain {
+			format = "text"
+		}
+	case "json", "text", "prometheus":
+	default:
+		c.Error(service.NewValidationError("format", "must be json, text or prometheus"))
+		return
+	}
+	
+	health := h.healthService.GetHealth(c.Request.Context())
+	
+	// Scrapes expect 200 whatever the health; the status is in the gauges
+	if format == "prometheus" {
+		metrics.HealthHandler(health).ServeHTTP(c.Writer, c.Request)
+		return
+	}
 	
 	// Set appropriate HTTP status code based on health
 	var statusCode int
@@ -42,6 +65,10 @@ func (h *HealthHandler) GetHealth(c *gin.Context) {
 		statusCode = http.StatusServiceUnavailable
 	}
 	
+	if format == "text" {
+		c.String(statusCode, "%s\n", nagiosLine(health))
+		return
+	}
 	c.JSON(statusCode, health)
 }
 
@@ -59,14 +86,14 @@ func (h *HealthHandler) GetLiveness(c *gin.Context) {
 
 // GetReadiness godoc
 // @Summary Readiness probe endpoint
-// @Description Kubernetes readiness probe - indicates if the service is ready to accept traffic
+// @Description Kubernetes readiness probe - indicates if the service is ready to accept traffic. A degraded critical component, such as a slow database, still passes: every replica shares it, so failing would pull them all from the load balancer at once.
 // @Tags health
 // @Produce json
-// @Success 200 {object} models.ReadinessResponse "Service is ready"
+// @Success 200 {object} models.ReadinessResponse "Service is ready, possibly degraded"
 // @Success 503 {object} models.ReadinessResponse "Service is not ready"
 // @Router /health/ready [get]
 func (h *HealthHandler) GetReadiness(c *gin.Context) {
-	readiness := h.healthService.GetReadiness()
+	readiness := h.healthService.GetReadiness(c.Request.Context())
 	
 	// Set appropriate HTTP status code based on readiness
 	var statusCode int
@@ -74,7 +101,7 @@ func (h *HealthHandler) GetReadiness(c *gin.Context) {
 	case models.HealthStatusHealthy:
 		statusCode = http.StatusOK
 	case models.HealthStatusDegraded:
-		statusCode = http.StatusServiceUnavailable
+		statusCode = http.StatusOK
 	case models.HealthStatusUnhealthy:
 		statusCode = http.StatusServiceUnavailable
 	default:
@@ -84,15 +111,34 @@ func (h *HealthHandler) GetReadiness(c *gin.Context) {
 	c.JSON(statusCode, readiness)
 }
 
+// GetStartup godoc
+// @Summary Startup probe endpoint
+// @Description Kubernetes startup probe - reports the progress of the boot phases: loading config, connecting to the database, running migrations and warming up. The server answers while booting, so the probe sees starting rather than a refused connection.
+// @Tags health
+// @Produce json
+// @Success 200 {object} models.StartupResponse "Every phase is complete"
+// @Success 503 {object} models.StartupResponse "Service is starting or a phase failed"
+// @Router /health/startup [get]
+func (h *HealthHandler) GetStartup(c *gin.Context) {
+	startup := h.healthService.GetStartup()
+	
+	statusCode := http.StatusServiceUnavailable
+	if startup.Status == models.StartupStatusStarted {
+		statusCode = http.StatusOK
+	}
+	
+	c.JSON(statusCode, startup)
+}
+
 // GetComponentHealth godoc
 // @Summary Get specific component health
 // @Description Get health status of a specific component
 // @Tags health
 // @Produce json
-// @Param component path string true "Component name (database, memory, goroutines)"
+// @Param component path string true "Registered component name, such as database, memory or goroutines"
 // @Success 200 {object} models.ComponentHealth "Component is healthy"
 // @Success 503 {object} models.ComponentHealth "Component is degraded or unhealthy"
-// @Success 404 {object} map[string]string "Component not found"
+// @Failure 404 {object} models.Problem "No such component"
 // @Router /health/component/{component} [get]
 func (h *HealthHandler) GetComponentHealth(c *gin.Context) {
 	componentName := c.Param("component")
@@ -104,7 +150,11 @@ func (h *HealthHandler) GetComponentHealth(c *gin.Context) {
 		return
 	}
 	
-	componentHealth := h.healthService.CheckComponent(componentName)
+	componentHealth, err := h.healthService.CheckComponent(c.Request.Context(), componentName)
+	if err != nil {
+		c.Error(err)
+		return
+	}
 	
 	// Set appropriate HTTP status code based on component health
 	var statusCode int
@@ -122,6 +172,25 @@ func (h *HealthHandler) GetComponentHealth(c *gin.Context) {
 	c.JSON(statusCode, componentHealth)
 }
 
+// GetHealthHistory godoc
+// @Summary Get recent health check results
+// @Description List the recent check results of every component, oldest first, and whether each component is flapping between statuses
+// @Tags health
+// @Produce json
+// @Param component query string false "Only this component"
+// @Success 200 {object} models.HealthHistoryResponse
+// @Failure 404 {object} models.Problem "No such component"
+// @Router /health/history [get]
+func (h *HealthHandler) GetHealthHistory(c *gin.Context) {
+	history, err := h.healthService.GetHistory(c.Query("component"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	
+	c.JSON(http.StatusOK, history)
+}
+
 // GetHealthSimple godoc
 // @Summary Simple health check
 // @Description Simple health check that returns OK if service is running
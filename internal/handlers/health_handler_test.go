@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"postService/internal/config"
+	"postService/internal/middleware"
+	"postService/internal/models"
+	"postService/internal/service"
+)
+
+// stubChecker reports a fixed status
+type stubChecker struct {
+	name     string
+	critical bool
+	status   models.HealthStatus
+}
+
+func (c stubChecker) Name() string           { return c.name }
+func (c stubChecker) Critical() bool         { return c.critical }
+func (c stubChecker) Timeout() time.Duration { return time.Second }
+
+func (c stubChecker) Check(ctx context.Context) models.ComponentHealth {
+	return models.ComponentHealth{Status: c.status, Details: map[string]interface{}{}}
+}
+
+// newHealthRouter serves the health endpoints for checkers, once every
+// phase tracked by startup is complete
+func newHealthRouter(startup *service.StartupTracker, checkers ...service.Checker) *gin.Engine {
+	registry := service.NewHealthRegistry()
+	registry.Register(checkers...)
+	h := NewHealthHandler(service.NewHealthService(registry, startup, "test", config.Default().Health))
+
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+	router.GET("/health", h.GetHealth)
+	router.GET("/health/ready", h.GetReadiness)
+	router.GET("/health/component/:component", h.GetComponentHealth)
+	return router
+}
+
+func serve(router http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func get(router http.Handler, path string) *httptest.ResponseRecorder {
+	return serve(router, httptest.NewRequest(http.MethodGet, path, nil))
+}
+
+func TestGetReadinessIgnoresDegradation(t *testing.T) {
+	tests := []struct {
+		name      string
+		checkers  []service.Checker
+		readiness int
+		ready     models.HealthStatus
+		health    int
+	}{
+		{"healthy", []service.Checker{
+			stubChecker{"database", true, models.HealthStatusHealthy},
+		}, http.StatusOK, models.HealthStatusHealthy, http.StatusOK},
+		// A slow or failed-over database is shared by every replica, so it
+		// shows on /health without pulling them all from the load balancer
+		{"critical degraded", []service.Checker{
+			stubChecker{"database", true, models.HealthStatusDegraded},
+		}, http.StatusOK, models.HealthStatusDegraded, http.StatusServiceUnavailable},
+		{"critical unhealthy", []service.Checker{
+			stubChecker{"database", true, models.HealthStatusUnhealthy},
+		}, http.StatusServiceUnavailable, models.HealthStatusUnhealthy, http.StatusServiceUnavailable},
+		{"non-critical unhealthy", []service.Checker{
+			stubChecker{"database", true, models.HealthStatusHealthy},
+			stubChecker{"memory", false, models.HealthStatusUnhealthy},
+		}, http.StatusOK, models.HealthStatusHealthy, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newHealthRouter(service.NewStartupTracker(), tt.checkers...)
+
+			w := get(router, "/health/ready")
+			if w.Code != tt.readiness {
+				t.Errorf("readiness status = %d, want %d", w.Code, tt.readiness)
+			}
+			var readiness models.ReadinessResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &readiness); err != nil {
+				t.Fatal(err)
+			}
+			if readiness.Status != tt.ready {
+				t.Errorf("readiness = %s, want %s", readiness.Status, tt.ready)
+			}
+
+			if w := get(router, "/health"); w.Code != tt.health {
+				t.Errorf("health status = %d, want %d", w.Code, tt.health)
+			}
+		})
+	}
+}
+
+func TestGetComponentHealth(t *testing.T) {
+	router := newHealthRouter(service.NewStartupTracker(),
+		stubChecker{"database", true, models.HealthStatusHealthy},
+		stubChecker{"memory", false, models.HealthStatusDegraded},
+	)
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/health/component/database", http.StatusOK},
+		{"/health/component/memory", http.StatusServiceUnavailable},
+		{"/health/component/disk", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		w := get(router, tt.path)
+		if w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.path, w.Code, tt.status)
+		}
+	}
+
+	w := get(router, "/health/component/disk")
+	if problem := decodeProblem(t, w); problem.Status != http.StatusNotFound {
+		t.Errorf("problem = %+v, want a 404 problem", problem)
+	}
+
+	var component models.ComponentHealth
+	if err := json.Unmarshal(get(router, "/health/component/memory").Body.Bytes(), &component); err != nil {
+		t.Fatal(err)
+	}
+	if component.Name != "memory" || component.Status != models.HealthStatusDegraded {
+		t.Errorf("component = %+v, want memory degraded", component)
+	}
+}
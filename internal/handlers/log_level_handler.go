@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"postService/internal/logging"
+	"postService/internal/models"
+	"postService/internal/service"
+)
+
+type LogLevelHandler struct{}
+
+func NewLogLevelHandler() *LogLevelHandler {
+	return &LogLevelHandler{}
+}
+
+// GetLogLevel godoc
+// @Summary Show the log level
+// @Description Show the level below which log records are dropped. Requires the admin token.
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Success 200 {object} models.LogLevel
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Router /admin/log-level [get]
+func (h *LogLevelHandler) GetLogLevel(c *gin.Context) {
+	c.JSON(http.StatusOK, models.LogLevel{Level: logging.Level()})
+}
+
+// SetLogLevel godoc
+// @Summary Change the log level
+// @Description Change the log level of this instance until it restarts, such as to debug to see every SQL statement. Requires the admin token.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param level body models.LogLevel true "New level"
+// @Success 200 {object} models.LogLevel
+// @Failure 400 {object} models.Problem
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Router /admin/log-level [put]
+func (h *LogLevelHandler) SetLogLevel(c *gin.Context) {
+	var req models.LogLevel
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(validationError(err))
+		return
+	}
+	if err := logging.SetLevel(req.Level); err != nil {
+		c.Error(service.NewValidationError("level", err.Error()))
+		return
+	}
+	slog.InfoContext(c.Request.Context(), "Log level changed", "level", req.Level)
+
+	c.JSON(http.StatusOK, models.LogLevel{Level: logging.Level()})
+}
@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"net/http"
+	"net/url"
+	"path"
 
 	"postService/internal/models"
 	"postService/internal/service"
@@ -19,28 +21,34 @@ func NewPostHandler(service service.PostService) *PostHandler {
 
 // CreatePost godoc
 // @Summary Create a new post
-// @Description Create a new post with title, content, and author
+// @Description Create a new post with title, content, and author. Posts start as drafts unless status is published or a publish_at time is given.
 // @Tags posts
 // @Accept json
 // @Produce json
 // @Param post body models.CreatePostRequest true "Post data"
 // @Success 201 {object} models.Post
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Header 201 {string} ETag "Post version"
+// @Failure 400 {object} models.Problem
+// @Failure 500 {object} models.Problem
 // @Router /posts [post]
 func (h *PostHandler) CreatePost(c *gin.Context) {
 	var req models.CreatePostRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(validationError(err))
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.Error(validationError(err))
 		return
 	}
 
-	post, err := h.service.CreatePost(req)
+	post, err := h.service.CreatePost(c.Request.Context(), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
+	setETag(c, post)
 	c.JSON(http.StatusCreated, post)
 }
 
@@ -51,85 +59,261 @@ func (h *PostHandler) CreatePost(c *gin.Context) {
 // @Produce json
 // @Param id path string true "Post ID"
 // @Success 200 {object} models.Post
-// @Failure 404 {object} map[string]string
+// @Header 200 {string} ETag "Post version, to be sent back in If-Match"
+// @Failure 404 {object} models.Problem
 // @Router /posts/{id} [get]
 func (h *PostHandler) GetPost(c *gin.Context) {
 	id := c.Param("id")
 	
-	post, err := h.service.GetPost(id)
+	post, err := h.service.GetPost(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	setETag(c, post)
+	c.JSON(http.StatusOK, post)
+}
+
+// GetPostBySlug godoc
+// @Summary Get a post by slug
+// @Description Get a single post by its slug. Slugs the post had before a title change redirect to its current slug.
+// @Tags posts
+// @Produce json
+// @Param slug path string true "Post slug"
+// @Success 200 {object} models.Post
+// @Header 200 {string} ETag "Post version, to be sent back in If-Match"
+// @Success 301 "The slug is outdated; Location holds the current one"
+// @Header 301 {string} Location "URL of the post under its current slug"
+// @Failure 404 {object} models.Problem
+// @Router /posts/by-slug/{slug} [get]
+func (h *PostHandler) GetPostBySlug(c *gin.Context) {
+	slug := c.Param("slug")
+
+	post, err := h.service.GetPostBySlug(c.Request.Context(), slug)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	if post.Slug != slug {
+		c.Redirect(http.StatusMovedPermanently, path.Join(path.Dir(c.Request.URL.Path), url.PathEscape(post.Slug)))
 		return
 	}
 
+	setETag(c, post)
 	c.JSON(http.StatusOK, post)
 }
 
 // GetAllPosts godoc
 // @Summary Get all posts
-// @Description Get a list of all posts
+// @Description Get a filtered and sorted page of published posts. Pass next_cursor from the previous page to fetch the next one.
 // @Tags posts
 // @Produce json
-// @Success 200 {array} models.Post
-// @Failure 500 {object} map[string]string
+// @Param author query string false "Only posts by this author"
+// @Param created_after query string false "Only posts created after this RFC 3339 timestamp"
+// @Param created_before query string false "Only posts created before this RFC 3339 timestamp"
+// @Param updated_since query string false "Only posts updated at or after this RFC 3339 timestamp"
+// @Param tag query []string false "Only posts with these tags; repeat the parameter for several tags" collectionFormat(multi)
+// @Param tag_match query string false "Whether posts need any or all of the tags" Enums(any, all) default(any)
+// @Param sort query string false "Comma separated sort fields (title, author, created_at, updated_at); prefix with - for descending" default(-created_at)
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Opaque cursor returned as next_cursor by the previous page"
+// @Success 200 {object} models.PostPage
+// @Failure 400 {object} models.Problem
+// @Failure 500 {object} models.Problem
 // @Router /posts [get]
 func (h *PostHandler) GetAllPosts(c *gin.Context) {
-	posts, err := h.service.GetAllPosts()
+	query, err := parsePostQuery(c, false)
+	if err != nil {
+		c.Error(validationError(err))
+		return
+	}
+	query.Status = models.PostStatusPublished
+
+	posts, err := h.service.ListPosts(c.Request.Context(), query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, posts)
 }
 
+// GetTrash godoc
+// @Summary List deleted posts
+// @Description Get a page of posts in the trash, most recently deleted first. Accepts the same filters as the post listing and may additionally sort by deleted_at.
+// @Tags posts
+// @Produce json
+// @Param author query string false "Only posts by this author"
+// @Param created_after query string false "Only posts created after this RFC 3339 timestamp"
+// @Param created_before query string false "Only posts created before this RFC 3339 timestamp"
+// @Param updated_since query string false "Only posts updated at or after this RFC 3339 timestamp"
+// @Param sort query string false "Comma separated sort fields (title, author, created_at, updated_at, deleted_at); prefix with - for descending" default(-deleted_at)
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Opaque cursor returned as next_cursor by the previous page"
+// @Success 200 {object} models.PostPage
+// @Failure 400 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Router /posts/trash [get]
+func (h *PostHandler) GetTrash(c *gin.Context) {
+	query, err := parsePostQuery(c, true)
+	if err != nil {
+		c.Error(validationError(err))
+		return
+	}
+
+	posts, err := h.service.ListPosts(c.Request.Context(), query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, posts)
+}
+
+// SearchPosts godoc
+// @Summary Search posts
+// @Description Full-text search over published post titles and content, ranked by relevance with highlighted snippets. Supports quoted phrases, "or" and "-" exclusions.
+// @Tags posts
+// @Produce json
+// @Param q query string true "Search query"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Number of results to skip"
+// @Success 200 {object} models.PostSearchResults
+// @Failure 400 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Router /posts/search [get]
+func (h *PostHandler) SearchPosts(c *gin.Context) {
+	query, err := parseSearchQuery(c)
+	if err != nil {
+		c.Error(validationError(err))
+		return
+	}
+
+	results, err := h.service.SearchPosts(c.Request.Context(), query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
 // UpdatePost godoc
 // @Summary Update a post
-// @Description Update an existing post by ID
+// @Description Update an existing post by ID. Send the ETag from a previous read in If-Match to avoid overwriting concurrent changes.
 // @Tags posts
 // @Accept json
 // @Produce json
 // @Param id path string true "Post ID"
+// @Param If-Match header string false "ETag of the version being updated"
 // @Param post body models.UpdatePostRequest true "Updated post data"
 // @Success 200 {object} models.Post
-// @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Header 200 {string} ETag "New post version"
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 412 {object} models.Problem
+// @Failure 500 {object} models.Problem
 // @Router /posts/{id} [put]
 func (h *PostHandler) UpdatePost(c *gin.Context) {
 	id := c.Param("id")
 	
+	version, err := parseIfMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	
 	var req models.UpdatePostRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(validationError(err))
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.Error(validationError(err))
 		return
 	}
 
-	post, err := h.service.UpdatePost(id, req)
+	post, err := h.service.UpdatePost(c.Request.Context(), id, req, version)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
+	setETag(c, post)
 	c.JSON(http.StatusOK, post)
 }
 
 // DeletePost godoc
 // @Summary Delete a post
-// @Description Delete a post by ID
+// @Description Move a post to the trash by ID. It can be brought back with the restore endpoint. Send the ETag from a previous read in If-Match to avoid deleting a post that changed since.
 // @Tags posts
 // @Param id path string true "Post ID"
+// @Param If-Match header string false "ETag of the version being deleted"
 // @Success 204
-// @Failure 404 {object} map[string]string
+// @Failure 404 {object} models.Problem
+// @Failure 412 {object} models.Problem
 // @Router /posts/{id} [delete]
 func (h *PostHandler) DeletePost(c *gin.Context) {
 	id := c.Param("id")
 	
-	err := h.service.DeletePost(id)
+	version, err := parseIfMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	
+	err = h.service.DeletePost(c.Request.Context(), id, version)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.Status(http.StatusNoContent)
-}
\ No newline at end of file
+}
+
+// RestorePost godoc
+// @Summary Restore a deleted post
+// @Description Move a post out of the trash
+// @Tags posts
+// @Produce json
+// @Param id path string true "Post ID"
+// @Success 200 {object} models.Post
+// @Header 200 {string} ETag "New post version"
+// @Failure 404 {object} models.Problem
+// @Router /posts/{id}/restore [post]
+func (h *PostHandler) RestorePost(c *gin.Context) {
+	id := c.Param("id")
+
+	post, err := h.service.RestorePost(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	setETag(c, post)
+	c.JSON(http.StatusOK, post)
+}
+
+// PurgePost godoc
+// @Summary Permanently delete a post
+// @Description Permanently remove a post that is already in the trash. Requires the admin token.
+// @Tags admin
+// @Param id path string true "Post ID"
+// @Security AdminToken
+// @Success 204
+// @Failure 401 {object} models.Problem
+// @Failure 403 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Router /admin/posts/{id} [delete]
+func (h *PostHandler) PurgePost(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := h.service.PurgePost(c.Request.Context(), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"postService/internal/middleware"
+	"postService/internal/models"
+	"postService/internal/repository"
+	"postService/internal/service"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// newTestRouter serves h's endpoints in front of an in-memory repository,
+// with problem responses rendered the way the server renders them
+func newTestRouter(register func(router *gin.Engine, h *PostHandler)) (*gin.Engine, service.PostService) {
+	posts := service.NewPostService(repository.NewInMemoryPostRepository())
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+	register(router, NewPostHandler(posts))
+	return router, posts
+}
+
+func decodeProblem(t *testing.T, w *httptest.ResponseRecorder) models.Problem {
+	t.Helper()
+	var problem models.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("response %q is not a problem: %v", w.Body.String(), err)
+	}
+	return problem
+}
+
+func TestSearchPostsValidation(t *testing.T) {
+	router, _ := newTestRouter(func(router *gin.Engine, h *PostHandler) {
+		router.GET("/posts/search", h.SearchPosts)
+	})
+
+	tests := []struct {
+		name  string
+		query url.Values
+		field string
+	}{
+		{"missing query", url.Values{}, "q"},
+		{"blank query", url.Values{"q": {"   "}}, "q"},
+		{"punctuation only", url.Values{"q": {`"" - !?`}}, "q"},
+		{"non-numeric limit", url.Values{"q": {"go"}, "limit": {"ten"}}, "limit"},
+		{"zero limit", url.Values{"q": {"go"}, "limit": {"0"}}, "limit"},
+		{"negative offset", url.Values{"q": {"go"}, "offset": {"-1"}}, "offset"},
+		{"non-numeric offset", url.Values{"q": {"go"}, "offset": {"next"}}, "offset"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/posts/search?"+tt.query.Encode(), nil))
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400; body %s", w.Code, w.Body.String())
+			}
+			problem := decodeProblem(t, w)
+			if len(problem.Errors) != 1 || problem.Errors[0].Field != tt.field {
+				t.Errorf("errors = %+v, want one for %s", problem.Errors, tt.field)
+			}
+		})
+	}
+}
+
+func TestSearchPostsAcceptsOperators(t *testing.T) {
+	router, _ := newTestRouter(func(router *gin.Engine, h *PostHandler) {
+		router.GET("/posts/search", h.SearchPosts)
+	})
+
+	for _, q := range []string{"go", `"go tips" -rust`, "go or rust", "  go  "} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/posts/search?"+url.Values{"q": {q}}.Encode(), nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("search %q: status = %d, want 200; body %s", q, w.Code, w.Body.String())
+		}
+	}
+}
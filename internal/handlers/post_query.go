@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"postService/internal/models"
+	"postService/internal/service"
+)
+
+// trashSort is the default order of the trash listing
+var trashSort = []models.SortField{{Field: "deleted_at", Desc: true}}
+
+// parsePostQuery reads the listing filters, sort and pagination parameters
+// from the request query string. deleted selects the trash instead of live posts.
+func parsePostQuery(c *gin.Context, deleted bool) (models.PostQuery, error) {
+	query := models.PostQuery{
+		Author:  c.Query("author"),
+		Deleted: deleted,
+	}
+
+	var err error
+	if query.CreatedAfter, err = parseTimeParam(c, "created_after"); err != nil {
+		return query, err
+	}
+	if query.CreatedBefore, err = parseTimeParam(c, "created_before"); err != nil {
+		return query, err
+	}
+	if query.UpdatedSince, err = parseTimeParam(c, "updated_since"); err != nil {
+		return query, err
+	}
+	if query.Tags, query.TagMatch, err = parseTagParams(c); err != nil {
+		return query, err
+	}
+	if query.Sort, err = models.ParseSort(c.Query("sort")); err != nil {
+		return query, err
+	}
+	if deleted && len(query.Sort) == 0 {
+		query.Sort = trashSort
+	}
+
+	limit, err := parseLimitParam(c)
+	if err != nil {
+		return query, err
+	}
+	if query.Page, err = models.NewPageRequest(limit, c.Query("cursor")); err != nil {
+		return query, err
+	}
+
+	return query, query.Validate()
+}
+
+// parseTagParams reads the repeatable tag parameter and whether posts need any
+// or all of the tags
+func parseTagParams(c *gin.Context) ([]string, models.TagMatch, error) {
+	tags := models.NormalizeTags(c.QueryArray("tag"))
+	if err := models.ValidateTags(tags); err != nil {
+		return nil, "", err
+	}
+
+	match := models.TagMatch(c.DefaultQuery("tag_match", string(models.TagMatchAny)))
+	if match != models.TagMatchAny && match != models.TagMatchAll {
+		return nil, "", service.NewValidationError("tag_match", fmt.Sprintf("must be %q or %q", models.TagMatchAny, models.TagMatchAll))
+	}
+	return tags, match, nil
+}
+
+// parseTimeParam parses an optional RFC 3339 timestamp query parameter
+func parseTimeParam(c *gin.Context, name string) (*time.Time, error) {
+	raw := c.Query(name)
+	if raw == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, service.NewValidationError(name, "must be an RFC 3339 timestamp")
+	}
+	return &t, nil
+}
+
+// parseSearchQuery reads the full-text search parameters from the request query string
+func parseSearchQuery(c *gin.Context) (models.SearchQuery, error) {
+	query := models.SearchQuery{
+		Query: strings.TrimSpace(c.Query("q")),
+	}
+	if query.Query == "" {
+		return query, service.NewValidationError("q", "is required")
+	}
+	// Punctuation alone parses to an empty search, which would match nothing
+	if !strings.ContainsFunc(query.Query, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) {
+		return query, service.NewValidationError("q", "must contain a word to search for")
+	}
+
+	var err error
+	if query.Limit, err = parseLimitParam(c); err != nil {
+		return query, err
+	}
+	if query.Limit == 0 {
+		query.Limit = models.DefaultPageLimit
+	}
+	if query.Limit > models.MaxPageLimit {
+		query.Limit = models.MaxPageLimit
+	}
+
+	if raw := c.Query("offset"); raw != "" {
+		if query.Offset, err = strconv.Atoi(raw); err != nil || query.Offset < 0 {
+			return query, service.NewValidationError("offset", "must be a non-negative integer")
+		}
+	}
+	return query, nil
+}
+
+// parseLimitParam parses the optional limit query parameter, returning 0 when it is not set
+func parseLimitParam(c *gin.Context) (int, error) {
+	raw := c.Query("limit")
+	if raw == "" {
+		return 0, nil
+	}
+
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 {
+		return 0, service.NewValidationError("limit", "must be a positive integer")
+	}
+	return limit, nil
+}
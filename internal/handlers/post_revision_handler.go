@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"postService/internal/models"
+	"postService/internal/service"
+)
+
+// ListRevisions godoc
+// @Summary List post revisions
+// @Description Get every revision of a post, newest first. Revision numbers match the post version they produced.
+// @Tags revisions
+// @Produce json
+// @Param id path string true "Post ID"
+// @Success 200 {array} models.PostRevision
+// @Failure 404 {object} models.Problem
+// @Router /posts/{id}/revisions [get]
+func (h *PostHandler) ListRevisions(c *gin.Context) {
+	id := c.Param("id")
+
+	revisions, err := h.service.ListRevisions(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+// GetRevision godoc
+// @Summary Get a post revision
+// @Description Get a single revision of a post
+// @Tags revisions
+// @Produce json
+// @Param id path string true "Post ID"
+// @Param rev path int true "Revision number"
+// @Success 200 {object} models.PostRevision
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Router /posts/{id}/revisions/{rev} [get]
+func (h *PostHandler) GetRevision(c *gin.Context) {
+	id := c.Param("id")
+
+	rev, err := parseRevision(c.Param("rev"), "rev")
+	if err != nil {
+		c.Error(validationError(err))
+		return
+	}
+
+	revision, err := h.service.GetRevision(c.Request.Context(), id, rev)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, revision)
+}
+
+// DiffRevisions godoc
+// @Summary Diff two post revisions
+// @Description Get the line-level difference of title, author and content between two revisions of a post
+// @Tags revisions
+// @Produce json
+// @Param id path string true "Post ID"
+// @Param from query int true "Base revision number"
+// @Param to query int true "Target revision number"
+// @Success 200 {object} models.RevisionDiff
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Router /posts/{id}/revisions/diff [get]
+func (h *PostHandler) DiffRevisions(c *gin.Context) {
+	id := c.Param("id")
+
+	from, err := parseRevision(c.Query("from"), "from")
+	if err != nil {
+		c.Error(validationError(err))
+		return
+	}
+	to, err := parseRevision(c.Query("to"), "to")
+	if err != nil {
+		c.Error(validationError(err))
+		return
+	}
+
+	diff, err := h.service.DiffRevisions(c.Request.Context(), id, from, to)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// RestoreRevision godoc
+// @Summary Roll a post back to a revision
+// @Description Restore the title, content and author of an earlier revision. The rollback is recorded as a new revision.
+// @Tags revisions
+// @Accept json
+// @Produce json
+// @Param id path string true "Post ID"
+// @Param rev path int true "Revision number to restore"
+// @Param If-Match header string false "ETag of the version being replaced"
+// @Param request body models.RestoreRevisionRequest false "Editor performing the rollback"
+// @Success 200 {object} models.Post
+// @Header 200 {string} ETag "New post version"
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 412 {object} models.Problem
+// @Router /posts/{id}/revisions/{rev}/restore [post]
+func (h *PostHandler) RestoreRevision(c *gin.Context) {
+	id := c.Param("id")
+
+	rev, err := parseRevision(c.Param("rev"), "rev")
+	if err != nil {
+		c.Error(validationError(err))
+		return
+	}
+
+	version, err := parseIfMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req models.RestoreRevisionRequest
+	if err := bindOptionalJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	post, err := h.service.RestoreRevision(c.Request.Context(), id, rev, req.Editor, version)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	setETag(c, post)
+	c.JSON(http.StatusOK, post)
+}
+
+// parseRevision parses a revision number from a path or query parameter
+func parseRevision(raw, name string) (int, error) {
+	rev, err := strconv.Atoi(raw)
+	if err != nil || rev < 1 {
+		return 0, service.NewValidationError(name, "must be a positive revision number")
+	}
+	return rev, nil
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"postService/internal/models"
+)
+
+func TestRestoreRevisionBody(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   io.Reader
+		status int
+		editor string
+	}{
+		{"no body", nil, http.StatusOK, "ann"},
+		{"empty chunked body", chunked(""), http.StatusOK, "ann"},
+		{"chunked editor", chunked(`{"editor":"bob"}`), http.StatusOK, "bob"},
+		{"malformed chunked body", chunked(`{"editor":`), http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			router, posts := newTestRouter(func(router *gin.Engine, h *PostHandler) {
+				router.POST("/posts/:id/revisions/:rev/restore", h.RestoreRevision)
+			})
+			post, err := posts.CreatePost(ctx, models.CreatePostRequest{
+				Title:   "first",
+				Content: "content",
+				Author:  "ann",
+				Status:  models.PostStatusPublished,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := posts.UpdatePost(ctx, post.ID, models.UpdatePostRequest{Title: "second"}, 0); err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/posts/"+post.ID+"/revisions/1/restore", tt.body)
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d; body %s", w.Code, tt.status, w.Body.String())
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var restored models.Post
+			if err := json.Unmarshal(w.Body.Bytes(), &restored); err != nil {
+				t.Fatal(err)
+			}
+			if restored.Title != "first" {
+				t.Errorf("title = %q, want first", restored.Title)
+			}
+			revisions, err := posts.ListRevisions(ctx, post.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			latest := revisions[0]
+			for _, rev := range revisions {
+				if rev.Revision > latest.Revision {
+					latest = rev
+				}
+			}
+			if latest.Revision != 3 || latest.Editor != tt.editor {
+				t.Errorf("latest revision %d by %q, want 3 by %q", latest.Revision, latest.Editor, tt.editor)
+			}
+		})
+	}
+}
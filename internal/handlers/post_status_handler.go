@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"postService/internal/models"
+)
+
+// PublishPost godoc
+// @Summary Publish a post
+// @Description Publish a draft, scheduled or archived post immediately, or schedule it by passing a future publish_at
+// @Tags lifecycle
+// @Accept json
+// @Produce json
+// @Param id path string true "Post ID"
+// @Param If-Match header string false "ETag of the version being changed"
+// @Param request body models.PublishPostRequest false "Optional publish time"
+// @Success 200 {object} models.Post
+// @Header 200 {string} ETag "New post version"
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Failure 412 {object} models.Problem
+// @Router /posts/{id}/publish [post]
+func (h *PostHandler) PublishPost(c *gin.Context) {
+	var req models.PublishPostRequest
+	if err := bindOptionalJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.transition(c, func(ctx context.Context, id string, version int) (*models.Post, error) {
+		return h.service.PublishPost(ctx, id, req, version)
+	})
+}
+
+// UnpublishPost godoc
+// @Summary Unpublish a post
+// @Description Move a published or scheduled post back to draft
+// @Tags lifecycle
+// @Produce json
+// @Param id path string true "Post ID"
+// @Param If-Match header string false "ETag of the version being changed"
+// @Success 200 {object} models.Post
+// @Header 200 {string} ETag "New post version"
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Failure 412 {object} models.Problem
+// @Router /posts/{id}/unpublish [post]
+func (h *PostHandler) UnpublishPost(c *gin.Context) {
+	h.transition(c, h.service.UnpublishPost)
+}
+
+// ArchivePost godoc
+// @Summary Archive a post
+// @Description Archive a draft, scheduled or published post, removing it from the public listing
+// @Tags lifecycle
+// @Produce json
+// @Param id path string true "Post ID"
+// @Param If-Match header string false "ETag of the version being changed"
+// @Success 200 {object} models.Post
+// @Header 200 {string} ETag "New post version"
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Failure 412 {object} models.Problem
+// @Router /posts/{id}/archive [post]
+func (h *PostHandler) ArchivePost(c *gin.Context) {
+	h.transition(c, h.service.ArchivePost)
+}
+
+// transition runs a lifecycle change for the post in the path, honouring If-Match
+func (h *PostHandler) transition(c *gin.Context, change func(ctx context.Context, id string, expectedVersion int) (*models.Post, error)) {
+	id := c.Param("id")
+
+	version, err := parseIfMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	post, err := change(c.Request.Context(), id, version)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	setETag(c, post)
+	c.JSON(http.StatusOK, post)
+}
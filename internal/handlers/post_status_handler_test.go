@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"postService/internal/models"
+)
+
+// chunked hides the length of body, the way a chunked request arrives
+func chunked(body string) io.Reader {
+	return io.MultiReader(strings.NewReader(body))
+}
+
+func TestPublishPostBody(t *testing.T) {
+	publishAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+
+	tests := []struct {
+		name   string
+		body   io.Reader
+		status int
+		want   models.PostStatus
+	}{
+		{"no body", nil, http.StatusOK, models.PostStatusPublished},
+		{"empty body", strings.NewReader(""), http.StatusOK, models.PostStatusPublished},
+		{"empty chunked body", chunked(""), http.StatusOK, models.PostStatusPublished},
+		{"publish_at", strings.NewReader(`{"publish_at":"` + publishAt + `"}`), http.StatusOK, models.PostStatusScheduled},
+		{"chunked publish_at", chunked(`{"publish_at":"` + publishAt + `"}`), http.StatusOK, models.PostStatusScheduled},
+		{"malformed chunked body", chunked(`{"publish_at":`), http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, posts := newTestRouter(func(router *gin.Engine, h *PostHandler) {
+				router.POST("/posts/:id/publish", h.PublishPost)
+			})
+			draft, err := posts.CreatePost(context.Background(), models.CreatePostRequest{
+				Title:   "draft",
+				Content: "content",
+				Author:  "ann",
+				Status:  models.PostStatusDraft,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/posts/"+draft.ID+"/publish", tt.body)
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d; body %s", w.Code, tt.status, w.Body.String())
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var post models.Post
+			if err := json.Unmarshal(w.Body.Bytes(), &post); err != nil {
+				t.Fatal(err)
+			}
+			if post.Status != tt.want {
+				t.Errorf("post status = %s, want %s", post.Status, tt.want)
+			}
+		})
+	}
+}
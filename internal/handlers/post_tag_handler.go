@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultTagLimit is the number of tags listed when no limit is given
+const defaultTagLimit = 100
+
+// ListTags godoc
+// @Summary List tags
+// @Description Get tags with the number of published posts carrying them, most used first
+// @Tags tags
+// @Produce json
+// @Param limit query int false "Maximum number of tags (default 100)"
+// @Success 200 {array} models.TagCount
+// @Failure 400 {object} models.Problem
+// @Failure 500 {object} models.Problem
+// @Router /tags [get]
+func (h *PostHandler) ListTags(c *gin.Context) {
+	limit, err := parseLimitParam(c)
+	if err != nil {
+		c.Error(validationError(err))
+		return
+	}
+	if limit == 0 {
+		limit = defaultTagLimit
+	}
+
+	tags, err := h.service.ListTags(c.Request.Context(), limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
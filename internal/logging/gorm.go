@@ -0,0 +1,74 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// GormLogger sends GORM's output to slog. Statements are logged at debug
+// level, statements slower than the threshold at warn and failed ones at
+// error, each with the request ID of the context the query ran with.
+type GormLogger struct {
+	slowThreshold time.Duration
+}
+
+// NewGormLogger returns a GORM logger; a slowThreshold of 0 disables slow
+// query warnings
+func NewGormLogger(slowThreshold time.Duration) *GormLogger {
+	return &GormLogger{slowThreshold: slowThreshold}
+}
+
+// LogMode is part of logger.Interface. The level is controlled by SetLevel
+// instead, so it is ignored.
+func (l *GormLogger) LogMode(logger.LogLevel) logger.Interface {
+	return l
+}
+
+func (l *GormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	slog.InfoContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+func (l *GormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	slog.WarnContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+func (l *GormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	slog.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+// Trace is called after every statement. The SQL is only rendered when the
+// record will be written, since that interpolates the arguments.
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	elapsed := time.Since(begin)
+
+	var msg string
+	var lvl slog.Level
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		msg, lvl = "query failed", slog.LevelError
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold:
+		msg, lvl = "slow query", slog.LevelWarn
+	default:
+		msg, lvl = "query", slog.LevelDebug
+	}
+	if !slog.Default().Enabled(ctx, lvl) {
+		return
+	}
+
+	sql, rows := fc()
+	attrs := []slog.Attr{
+		slog.String("sql", sql),
+		slog.Int64("rows", rows),
+		slog.Float64("elapsed_ms", milliseconds(elapsed)),
+	}
+	if lvl == slog.LevelError {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	slog.LogAttrs(ctx, lvl, msg, attrs...)
+}
@@ -0,0 +1,96 @@
+// Package logging provides the service's structured JSON logger. Records
+// logged with a context carry the request ID and trace of the request they
+// belong to, and the level can be changed while the service runs.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Levels lists the names accepted by ParseLevel and SetLevel
+var Levels = []string{"debug", "info", "warn", "error"}
+
+// level is shared by every logger New returns, so SetLevel applies at once
+var level = new(slog.LevelVar)
+
+type requestIDKey struct{}
+
+// New returns a logger writing JSON lines to w at the current level
+func New(w io.Writer) *slog.Logger {
+	return slog.New(&contextHandler{
+		Handler: slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level}),
+	})
+}
+
+// ParseLevel converts a level name such as "info" to a slog level
+func ParseLevel(name string) (slog.Level, error) {
+	var l slog.Level
+	if !slices.Contains(Levels, name) || l.UnmarshalText([]byte(name)) != nil {
+		return 0, fmt.Errorf("%q is not one of %s", name, strings.Join(Levels, ", "))
+	}
+	return l, nil
+}
+
+// SetLevel changes the level of every logger from New
+func SetLevel(name string) error {
+	l, err := ParseLevel(name)
+	if err != nil {
+		return err
+	}
+	level.Set(l)
+	return nil
+}
+
+// Level returns the name of the current level
+func Level() string {
+	return strings.ToLower(level.Level().String())
+}
+
+// WithRequestID returns a context whose log records carry id
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the ID stored by WithRequestID, or ""
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// contextHandler adds the request ID and trace found in a record's context
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(
+			slog.String("trace_id", span.TraceID().String()),
+			slog.String("span_id", span.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
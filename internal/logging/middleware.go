@@ -0,0 +1,38 @@
+package logging
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccessLog logs one record per request once it has been served, replacing
+// gin's text logger. Server errors are logged at error level, client errors
+// at warn, and everything else at info.
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		lvl := slog.LevelInfo
+		switch {
+		case status >= 500:
+			lvl = slog.LevelError
+		case status >= 400:
+			lvl = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Int("bytes", max(c.Writer.Size(), 0)),
+			slog.Float64("duration_ms", milliseconds(time.Since(start))),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		slog.LogAttrs(c.Request.Context(), lvl, "request", attrs...)
+	}
+}
@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"postService/internal/models"
+)
+
+// healthStatusValues encodes statuses so that alerts can compare against a
+// threshold, such as health_component_status < 2
+var healthStatusValues = map[models.HealthStatus]float64{
+	models.HealthStatusHealthy:   2,
+	models.HealthStatusDegraded:  1,
+	models.HealthStatusUnhealthy: 0,
+}
+
+// HealthHandler serves a health report in the Prometheus text format: the
+// status and check duration of every component, and its numeric details.
+// The gauges are built from the report on each request rather than kept in
+// the service registry, so they always match what /health reports.
+func HealthHandler(health *models.HealthResponse) http.Handler {
+	status := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "health_component_status",
+		Help: "Status of a health component: 2 healthy, 1 degraded, 0 unhealthy.",
+	}, []string{"component"})
+	responseTime := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "health_component_response_ms",
+		Help: "How long the latest check of a health component took, in milliseconds.",
+	}, []string{"component"})
+	details := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "health_component_detail",
+		Help: "Numeric details reported by a health component, such as open database connections.",
+	}, []string{"component", "detail"})
+
+	for _, component := range health.Components {
+		status.WithLabelValues(component.Name).Set(healthStatusValues[component.Status])
+		responseTime.WithLabelValues(component.Name).Set(float64(component.ResponseTime))
+		for name, value := range component.Details {
+			if n, ok := number(value); ok {
+				details.WithLabelValues(component.Name, name).Set(n)
+			}
+		}
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(status, responseTime, details)
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// number converts the numeric types health details are reported in.
+// Durations are exported in seconds, the Prometheus base unit.
+func number(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case time.Duration:
+		return n.Seconds(), true
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"postService/internal/models"
+)
+
+func TestHealthHandlerGolden(t *testing.T) {
+	health := &models.HealthResponse{
+		Status: models.HealthStatusDegraded,
+		Components: []models.ComponentHealth{
+			{
+				Name:         "database",
+				Status:       models.HealthStatusDegraded,
+				ResponseTime: 12,
+				Details: map[string]interface{}{
+					"server_version": "16.2",
+					"in_use":         int(8),
+					"latency":        250 * time.Millisecond,
+					"latency_ms":     int64(250),
+				},
+			},
+			{
+				Name:         "memory",
+				Status:       models.HealthStatusHealthy,
+				ResponseTime: 0,
+				Details: map[string]interface{}{
+					"num_gc":             uint32(7),
+					"alloc_mb":           uint64(42),
+					"limit_used_percent": 40.5,
+					"ratio":              float32(0.25),
+					"pages":              uint(3),
+					"threads":            int32(-1),
+				},
+			},
+			// Label values are escaped in the exposition format
+			{Name: "odd \"name\"\\\n", Status: models.HealthStatusUnhealthy, ResponseTime: 5000},
+		},
+	}
+
+	w := httptest.NewRecorder()
+	HealthHandler(health).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health?format=prometheus", nil))
+
+	const want = `# HELP health_component_detail Numeric details reported by a health component, such as open database connections.
+# TYPE health_component_detail gauge
+health_component_detail{component="database",detail="in_use"} 8
+health_component_detail{component="database",detail="latency"} 0.25
+health_component_detail{component="database",detail="latency_ms"} 250
+health_component_detail{component="memory",detail="alloc_mb"} 42
+health_component_detail{component="memory",detail="limit_used_percent"} 40.5
+health_component_detail{component="memory",detail="num_gc"} 7
+health_component_detail{component="memory",detail="pages"} 3
+health_component_detail{component="memory",detail="ratio"} 0.25
+health_component_detail{component="memory",detail="threads"} -1
+# HELP health_component_response_ms How long the latest check of a health component took, in milliseconds.
+# TYPE health_component_response_ms gauge
+health_component_response_ms{component="database"} 12
+health_component_response_ms{component="memory"} 0
+health_component_response_ms{component="odd \"name\"\\\n"} 5000
+# HELP health_component_status Status of a health component: 2 healthy, 1 degraded, 0 unhealthy.
+# TYPE health_component_status gauge
+health_component_status{component="database"} 1
+health_component_status{component="memory"} 2
+health_component_status{component="odd \"name\"\\\n"} 0
+`
+	if got := w.Body.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestNumberConvertsEveryNumericType(t *testing.T) {
+	values := []interface{}{
+		int(2), int8(2), int16(2), int32(2), int64(2),
+		uint(2), uint8(2), uint16(2), uint32(2), uint64(2),
+		float32(2), float64(2), 2 * time.Second,
+	}
+	for _, value := range values {
+		if n, ok := number(value); !ok || n != 2 {
+			t.Errorf("number(%T %v) = %v, %v; want 2, true", value, value, n, ok)
+		}
+	}
+	for _, value := range []interface{}{"2", true, nil, []int{2}} {
+		if _, ok := number(value); ok {
+			t.Errorf("number(%T %v) converted a non-number", value, value)
+		}
+	}
+}
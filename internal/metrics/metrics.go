@@ -0,0 +1,115 @@
+// Package metrics exposes the service's Prometheus metrics: HTTP traffic,
+// the database connection pool, the Go runtime and post lifecycle events.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "postservice"
+
+// unmatchedRoute labels requests that matched no route, so arbitrary paths
+// cannot blow up the label cardinality
+const unmatchedRoute = "unmatched"
+
+// Metrics owns a registry with every collector of the service. It
+// implements service.PostMetrics.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	inFlight prometheus.Gauge
+
+	postsCreated prometheus.Counter
+	postsUpdated prometheus.Counter
+	postsDeleted prometheus.Counter
+}
+
+// New creates the collectors and registers them, along with the pool stats
+// of db and the Go runtime and process collectors
+func New(db *sql.DB) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests handled, by method, route template and status code.",
+		}, []string{"method", "route", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time taken to handle HTTP requests, by method, route template and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "http_requests_in_flight",
+			Help:      "HTTP requests currently being handled.",
+		}),
+		postsCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "posts_created_total",
+			Help:      "Posts created.",
+		}),
+		postsUpdated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "posts_updated_total",
+			Help:      "Posts updated.",
+		}),
+		postsDeleted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "posts_deleted_total",
+			Help:      "Posts moved to the trash.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		m.requests, m.duration, m.inFlight,
+		m.postsCreated, m.postsUpdated, m.postsDeleted,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	if db != nil {
+		m.registry.MustRegister(collectors.NewDBStatsCollector(db, "postservice"))
+	}
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Middleware counts and times every request, labelled by the route template
+// such as /api/v1/posts/:id rather than the raw path
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		m.inFlight.Inc()
+		defer m.inFlight.Dec()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		m.requests.WithLabelValues(c.Request.Method, route, status).Inc()
+		m.duration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+func (m *Metrics) PostCreated() { m.postsCreated.Inc() }
+func (m *Metrics) PostUpdated() { m.postsUpdated.Inc() }
+func (m *Metrics) PostDeleted() { m.postsDeleted.Inc() }
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("scrape status = %d", w.Code)
+	}
+	body, err := io.ReadAll(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestMiddlewareRecordsRequests(t *testing.T) {
+	m := New(nil)
+	router := gin.New()
+	router.Use(m.Middleware())
+	router.GET("/api/v1/posts/:id", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	for _, path := range []string{"/api/v1/posts/42", "/no/such/path"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	m.PostCreated()
+
+	body := scrape(t, m)
+	for _, want := range []string{
+		// Labelled by the route template, not the raw path
+		`postservice_http_requests_total{method="GET",route="/api/v1/posts/:id",status="204"} 1`,
+		`postservice_http_request_duration_seconds_count{method="GET",route="/api/v1/posts/:id",status="204"} 1`,
+		`postservice_http_request_duration_seconds_bucket{method="GET",route="/api/v1/posts/:id",status="204",le="+Inf"} 1`,
+		`postservice_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`postservice_http_requests_in_flight 0`,
+		`postservice_posts_created_total 1`,
+		`go_goroutines `,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("scrape is missing %s", want)
+		}
+	}
+	if strings.Contains(body, "/api/v1/posts/42") || strings.Contains(body, "/no/such/path") {
+		t.Error("scrape contains a raw request path")
+	}
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminAuth guards operator-only endpoints with a static bearer token. When no
+// token is configured the endpoints are disabled altogether.
+func AdminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			AbortWithProblem(c, http.StatusForbidden, "admin endpoints are disabled")
+			return
+		}
+
+		provided, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.Header("WWW-Authenticate", `Bearer realm="admin"`)
+			AbortWithProblem(c, http.StatusUnauthorized, "invalid admin token")
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"postService/internal/models"
+	"postService/internal/service"
+)
+
+// retryAfterSeconds is suggested to clients when storage is unavailable
+const retryAfterSeconds = "5"
+
+// ErrorHandler renders the last error a handler recorded with c.Error as an
+// RFC 7807 problem, choosing the status from the error kind. Handlers that
+// already wrote a response are left alone.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		problem := problemFor(err)
+		if problem.Status >= http.StatusInternalServerError {
+			slog.ErrorContext(c.Request.Context(), "request failed", "method", c.Request.Method, "path", c.Request.URL.Path, "error", err)
+		}
+		if problem.Status == http.StatusServiceUnavailable {
+			c.Header("Retry-After", retryAfterSeconds)
+		}
+		writeProblem(c, problem)
+	}
+}
+
+// NoRoute answers unknown routes with a problem instead of gin's plain text 404
+func NoRoute(c *gin.Context) {
+	AbortWithProblem(c, http.StatusNotFound, "no such endpoint")
+}
+
+// Starting answers requests that need the service to have finished booting
+func Starting(c *gin.Context) {
+	c.Header("Retry-After", retryAfterSeconds)
+	AbortWithProblem(c, http.StatusServiceUnavailable, "the service is starting")
+}
+
+// AbortWithProblem stops the chain and responds with a problem for status
+func AbortWithProblem(c *gin.Context, status int, detail string) {
+	c.Abort()
+	writeProblem(c, models.Problem{Status: status, Detail: detail})
+}
+
+func writeProblem(c *gin.Context, problem models.Problem) {
+	problem.Type = "about:blank"
+	problem.Title = http.StatusText(problem.Status)
+	problem.Instance = c.Request.URL.Path
+	problem.RequestID = GetRequestID(c)
+
+	c.Header("Content-Type", models.ProblemContentType)
+	c.JSON(problem.Status, problem)
+}
+
+// problemFor maps an error onto a problem. Only the messages of known error
+// kinds reach the client; anything else is reported as an internal error.
+func problemFor(err error) models.Problem {
+	var validationErr *service.ValidationError
+	if errors.As(err, &validationErr) {
+		return models.Problem{
+			Status: http.StatusBadRequest,
+			Detail: "the request has invalid fields",
+			Errors: validationErr.Fields,
+		}
+	}
+
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, service.ErrVersionMismatch):
+		status = http.StatusPreconditionFailed
+	case errors.Is(err, service.ErrValidation),
+		errors.Is(err, models.ErrInvalidCursor),
+		errors.Is(err, models.ErrInvalidSort):
+		status = http.StatusBadRequest
+	case errors.Is(err, service.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrConflict):
+		status = http.StatusConflict
+	case errors.Is(err, service.ErrUnavailable):
+		status = http.StatusServiceUnavailable
+	default:
+		return models.Problem{Status: status, Detail: "internal server error"}
+	}
+
+	// Domain errors carry a client-facing message; their cause stays in the logs
+	detail := err.Error()
+	var domainErr *service.DomainError
+	if errors.As(err, &domainErr) {
+		detail = domainErr.Message
+	}
+	return models.Problem{Status: status, Detail: detail}
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Recovery turns a panic in a handler into a 500 problem and logs it, with
+// its stack, as a structured record instead of gin's plain text dump
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, err interface{}) {
+		slog.ErrorContext(c.Request.Context(), "panic while handling request",
+			"method", c.Request.Method, "path", c.Request.URL.Path,
+			"error", err, "stack", string(debug.Stack()))
+		AbortWithProblem(c, http.StatusInternalServerError, "internal server error")
+	})
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"postService/internal/logging"
+)
+
+// RequestIDHeader carries the request ID in both directions
+const RequestIDHeader = "X-Request-ID"
+
+const requestIDKey = "request_id"
+
+// maxRequestIDLength bounds IDs supplied by callers so they cannot bloat logs
+const maxRequestIDLength = 128
+
+// RequestID tags every request with an ID, reusing the caller's X-Request-ID
+// when it is a reasonable value, and echoes it in the response. The ID is
+// also stored in the request context, so everything logged with it,
+// including SQL statements, carries the ID.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.New().String()
+		}
+
+		c.Set(requestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// GetRequestID returns the ID assigned by RequestID, or "" outside of it
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,198 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxCommentDepth is the deepest a reply can be nested; top-level comments are at depth 0
+const MaxCommentDepth = 8
+
+// Comment is a comment on a post or a reply to another comment. Replies
+// record the top-level comment of their thread in RootID so a whole thread
+// can be loaded at once.
+type Comment struct {
+	ID        string    `json:"id" gorm:"type:uuid;primary_key;index:idx_comments_post_thread,priority:3" example:"6f1e2d3c-4b5a-4978-8695-a4b3c2d1e0f9"`
+	PostID    string    `json:"post_id" gorm:"type:uuid;not null;index:idx_comments_post_thread,priority:1" example:"123e4567-e89b-12d3-a456-426614174000"`
+	ParentID  *string   `json:"parent_id,omitempty" gorm:"type:uuid;index" example:"5e0d1c2b-3a49-4867-9584-93a2b1c0d9e8"`
+	RootID    *string   `json:"root_id,omitempty" gorm:"type:uuid;index" example:"5e0d1c2b-3a49-4867-9584-93a2b1c0d9e8"`
+	Depth     int       `json:"depth" gorm:"not null;default:0" example:"1"`
+	Author    string    `json:"author" gorm:"not null" example:"Jane Doe"`
+	Body      string    `json:"body" gorm:"not null" example:"Great post!"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime;index:idx_comments_post_thread,priority:2" example:"2023-01-01T00:00:00Z"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime" example:"2023-01-01T00:00:00Z"`
+	// DeletedAt marks a tombstone: the author and body are cleared but the
+	// comment stays in place so its replies keep their thread
+	DeletedAt *time.Time `json:"deleted_at,omitempty" example:"2023-01-02T00:00:00Z"`
+	Replies   []*Comment `json:"replies,omitempty" gorm:"-"`
+
+	// Post and Parent only exist so that purging a post cascades to its comments
+	Post   *Post    `json:"-" gorm:"constraint:OnDelete:CASCADE" swaggerignore:"true"`
+	Parent *Comment `json:"-" gorm:"foreignKey:ParentID;constraint:OnDelete:CASCADE" swaggerignore:"true"`
+}
+
+func (c *Comment) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// ThreadID returns the ID of the top-level comment of the thread
+func (c *Comment) ThreadID() string {
+	if c.RootID != nil {
+		return *c.RootID
+	}
+	return c.ID
+}
+
+// Tombstone clears the content of a deleted comment
+func (c *Comment) Tombstone(now time.Time) {
+	c.Author = ""
+	c.Body = ""
+	c.DeletedAt = &now
+	c.UpdatedAt = now
+}
+
+type CreateCommentRequest struct {
+	Author string `json:"author" binding:"required" example:"Jane Doe"`
+	Body   string `json:"body" binding:"required" example:"Great post!"`
+	// ParentID makes the comment a reply to another comment on the same post
+	ParentID string `json:"parent_id,omitempty" example:"5e0d1c2b-3a49-4867-9584-93a2b1c0d9e8"`
+}
+
+type UpdateCommentRequest struct {
+	Body string `json:"body" binding:"required" example:"Great post, thanks!"`
+}
+
+// NewComment builds a comment on a post, as a reply to parent when it is not nil
+func NewComment(postID string, req CreateCommentRequest, parent *Comment) (*Comment, error) {
+	now := time.Now()
+	comment := &Comment{
+		ID:        uuid.New().String(),
+		PostID:    postID,
+		Author:    req.Author,
+		Body:      req.Body,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if parent != nil {
+		if parent.Depth >= MaxCommentDepth {
+			return nil, fmt.Errorf("cannot nest replies more than %d levels deep", MaxCommentDepth)
+		}
+		rootID := parent.ThreadID()
+		comment.ParentID = &parent.ID
+		comment.RootID = &rootID
+		comment.Depth = parent.Depth + 1
+	}
+	return comment, nil
+}
+
+// CommentQuery selects a page of top-level comments on a post, oldest first
+type CommentQuery struct {
+	PostID string
+	Page   PageRequest
+}
+
+// commentSort is the sort key recorded in comment cursors
+const commentSort = "created_at"
+
+// CursorTime decodes the created_at position of the cursor, if any
+func (q CommentQuery) CursorTime() (*time.Time, error) {
+	c := q.Page.Cursor
+	if c == nil {
+		return nil, nil
+	}
+	if c.Sort != commentSort || len(c.Values) != 1 {
+		return nil, ErrInvalidCursor
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, c.Values[0])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &t, nil
+}
+
+// IsAfterCursor reports whether a comment sorts strictly after the cursor
+// position. at must come from CursorTime.
+func (q CommentQuery) IsAfterCursor(c *Comment, at time.Time) bool {
+	if !c.CreatedAt.Equal(at) {
+		return c.CreatedAt.After(at)
+	}
+	return c.ID > q.Page.Cursor.ID
+}
+
+// LessComment orders comments oldest first, with the ID as the tiebreaker
+func LessComment(a, b *Comment) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.ID < b.ID
+}
+
+// CommentPage is a page of top-level comments, each carrying its nested replies
+type CommentPage struct {
+	Data []*Comment `json:"data"`
+	Page PageInfo   `json:"page"`
+}
+
+// NewCommentPage builds a page from up to limit+1 top-level comments sorted
+// oldest first, nesting replies under their parents. replies holds every
+// reply in the threads of those comments, in any order.
+func NewCommentPage(roots []*Comment, replies []*Comment, query CommentQuery) *CommentPage {
+	limit := query.Page.Limit
+	page := &CommentPage{
+		Data: roots,
+		Page: PageInfo{Limit: limit},
+	}
+
+	if len(roots) > limit {
+		page.Data = roots[:limit]
+		page.Page.HasMore = true
+		last := page.Data[len(page.Data)-1]
+		page.Page.NextCursor = Cursor{
+			Sort:   commentSort,
+			Values: []string{last.CreatedAt.Format(time.RFC3339Nano)},
+			ID:     last.ID,
+		}.Encode()
+	}
+	if page.Data == nil {
+		page.Data = []*Comment{}
+	}
+	page.Page.Count = len(page.Data)
+
+	nestReplies(page.Data, replies)
+	return page
+}
+
+// nestReplies attaches replies to their parents, oldest first at every level
+func nestReplies(roots []*Comment, replies []*Comment) {
+	byID := make(map[string]*Comment, len(roots)+len(replies))
+	for _, c := range roots {
+		c.Replies = []*Comment{}
+		byID[c.ID] = c
+	}
+	for _, c := range replies {
+		c.Replies = []*Comment{}
+		byID[c.ID] = c
+	}
+
+	sorted := append([]*Comment(nil), replies...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return LessComment(sorted[i], sorted[j])
+	})
+	for _, c := range sorted {
+		if c.ParentID == nil {
+			continue
+		}
+		if parent, ok := byID[*c.ParentID]; ok {
+			parent.Replies = append(parent.Replies, c)
+		}
+	}
+}
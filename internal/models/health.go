@@ -13,21 +13,51 @@ const (
 )
 
 type ComponentHealth struct {
-	Name         string            `json:"name" example:"database"`
-	Status       HealthStatus      `json:"status" example:"healthy"`
-	Message      string            `json:"message,omitempty" example:"Connection successful"`
-	ResponseTime int64             `json:"response_time_ms" example:"15"`
-	Details      map[string]string `json:"details,omitempty"`
-	Error        string            `json:"error,omitempty" example:"connection timeout"`
+	Name         string       `json:"name" example:"database"`
+	Status       HealthStatus `json:"status" example:"healthy"`
+	Message      string       `json:"message,omitempty" example:"Connection successful"`
+	ResponseTime int64        `json:"response_time_ms" example:"15"`
+	// Details holds numbers wherever a detail is a measurement, so they can
+	// be exported as metrics, and strings otherwise
+	Details map[string]interface{} `json:"details,omitempty"`
+	Error   string                 `json:"error,omitempty" example:"connection timeout"`
+	// CheckedAt is when the check ran; Stale is set when the background
+	// probe has not refreshed the result for longer than expected
+	CheckedAt time.Time `json:"checked_at" example:"2023-01-01T00:00:00Z"`
+	Stale     bool      `json:"stale" example:"false"`
 }
 
 type HealthResponse struct {
-	Status      HealthStatus      `json:"status" example:"healthy"`
-	Timestamp   time.Time         `json:"timestamp" example:"2023-01-01T00:00:00Z"`
-	Version     string            `json:"version" example:"1.0.0"`
-	Uptime      int64             `json:"uptime_seconds" example:"3600"`
-	Components  []ComponentHealth `json:"components"`
-	Summary     HealthSummary     `json:"summary"`
+	Status     HealthStatus      `json:"status" example:"healthy"`
+	Timestamp  time.Time         `json:"timestamp" example:"2023-01-01T00:00:00Z"`
+	Version    string            `json:"version" example:"1.0.0"`
+	Uptime     int64             `json:"uptime_seconds" example:"3600"`
+	Components []ComponentHealth `json:"components"`
+	Summary    HealthSummary     `json:"summary"`
+}
+
+// HealthSample is one recorded result of a component's check
+type HealthSample struct {
+	Timestamp    time.Time    `json:"timestamp" example:"2023-01-01T00:00:00Z"`
+	Status       HealthStatus `json:"status" example:"healthy"`
+	ResponseTime int64        `json:"response_time_ms" example:"15"`
+	Error        string       `json:"error,omitempty" example:"connection timeout"`
+}
+
+// ComponentHistory lists the recent results of a component, oldest first.
+// A component is flapping when its status changed often within them.
+type ComponentHistory struct {
+	Name        string         `json:"name" example:"database"`
+	Status      HealthStatus   `json:"status" example:"healthy"`
+	Transitions int            `json:"transitions" example:"0"`
+	Flapping    bool           `json:"flapping" example:"false"`
+	Samples     []HealthSample `json:"samples"`
+}
+
+type HealthHistoryResponse struct {
+	Timestamp       time.Time          `json:"timestamp" example:"2023-01-01T00:00:00Z"`
+	IntervalSeconds float64            `json:"interval_seconds" example:"10"`
+	Components      []ComponentHistory `json:"components"`
 }
 
 type HealthSummary struct {
@@ -48,4 +78,41 @@ type ReadinessResponse struct {
 	Timestamp  time.Time         `json:"timestamp" example:"2023-01-01T00:00:00Z"`
 	Message    string            `json:"message" example:"Service is ready"`
 	Components []ComponentHealth `json:"components"`
-}
\ No newline at end of file
+}
+
+type StartupStatus string
+
+const (
+	StartupStatusStarting StartupStatus = "starting"
+	StartupStatusStarted  StartupStatus = "started"
+	StartupStatusFailed   StartupStatus = "failed"
+)
+
+type StartupPhaseStatus string
+
+const (
+	StartupPhasePending  StartupPhaseStatus = "pending"
+	StartupPhaseRunning  StartupPhaseStatus = "running"
+	StartupPhaseComplete StartupPhaseStatus = "complete"
+	StartupPhaseFailed   StartupPhaseStatus = "failed"
+)
+
+// StartupPhase is one step of booting the service
+type StartupPhase struct {
+	Name        string             `json:"name" example:"migrations"`
+	Status      StartupPhaseStatus `json:"status" example:"running"`
+	StartedAt   *time.Time         `json:"started_at,omitempty" example:"2023-01-01T00:00:00Z"`
+	CompletedAt *time.Time         `json:"completed_at,omitempty" example:"2023-01-01T00:00:00Z"`
+	// Attempts counts tries of phases that are retried, such as connecting
+	// to the database, and Error is the latest failure
+	Attempts int    `json:"attempts,omitempty" example:"1"`
+	Error    string `json:"error,omitempty" example:"connection refused"`
+}
+
+// StartupResponse reports boot progress. Status is starting until every
+// phase is complete, then started.
+type StartupResponse struct {
+	Status    StartupStatus  `json:"status" example:"starting"`
+	Timestamp time.Time      `json:"timestamp" example:"2023-01-01T00:00:00Z"`
+	Phases    []StartupPhase `json:"phases"`
+}
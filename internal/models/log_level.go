@@ -0,0 +1,6 @@
+package models
+
+// LogLevel is the level below which log records are dropped
+type LogLevel struct {
+	Level string `json:"level" binding:"required" example:"info" enums:"debug,info,warn,error"`
+}
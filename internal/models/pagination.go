@@ -0,0 +1,104 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor marks the position of the last item of a page: the values of its
+// sort keys followed by its ID as the tiebreaker. Sort records the sort the
+// cursor was issued for so it cannot be replayed against a different order.
+// Clients only ever see its opaque encoded form.
+type Cursor struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+	ID     string   `json:"id"`
+}
+
+// Encode returns the opaque, URL-safe representation of the cursor
+func (c Cursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor parses a cursor previously produced by Encode
+func DecodeCursor(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+type PageRequest struct {
+	Limit  int
+	Cursor *Cursor
+}
+
+// NewPageRequest builds a PageRequest from raw query values, clamping the limit
+// to MaxPageLimit and falling back to DefaultPageLimit when it is not set.
+func NewPageRequest(limit int, cursor string) (PageRequest, error) {
+	page := PageRequest{Limit: limit}
+	if page.Limit <= 0 {
+		page.Limit = DefaultPageLimit
+	}
+	if page.Limit > MaxPageLimit {
+		page.Limit = MaxPageLimit
+	}
+
+	if cursor != "" {
+		c, err := DecodeCursor(cursor)
+		if err != nil {
+			return PageRequest{}, err
+		}
+		page.Cursor = c
+	}
+	return page, nil
+}
+
+type PageInfo struct {
+	Limit      int    `json:"limit" example:"20"`
+	Count      int    `json:"count" example:"20"`
+	HasMore    bool   `json:"has_more" example:"true"`
+	NextCursor string `json:"next_cursor,omitempty" example:"eyJzIjoiLWNyZWF0ZWRfYXQiLCJ2IjpbIjIwMjMtMDEtMDFUMDA6MDA6MDBaIl0sImlkIjoiMTIzIn0"`
+}
+
+type PostPage struct {
+	Data []*Post  `json:"data"`
+	Page PageInfo `json:"page"`
+}
+
+// NewPostPage builds a page from up to limit+1 posts already sorted in query
+// order. The extra post, if present, only signals that another page exists.
+func NewPostPage(posts []*Post, query PostQuery) *PostPage {
+	limit := query.Page.Limit
+	page := &PostPage{
+		Data: posts,
+		Page: PageInfo{Limit: limit},
+	}
+
+	if len(posts) > limit {
+		page.Data = posts[:limit]
+		page.Page.HasMore = true
+		page.Page.NextCursor = query.cursorAt(page.Data[len(page.Data)-1]).Encode()
+	}
+	if page.Data == nil {
+		page.Data = []*Post{}
+	}
+	page.Page.Count = len(page.Data)
+
+	return page
+}
@@ -8,12 +8,22 @@ import (
 )
 
 type Post struct {
-	ID        string    `json:"id" gorm:"type:uuid;primary_key" example:"123e4567-e89b-12d3-a456-426614174000"`
+	ID        string    `json:"id" gorm:"type:uuid;primary_key;index:idx_posts_created_at_id,priority:2" example:"123e4567-e89b-12d3-a456-426614174000"`
 	Title     string    `json:"title" gorm:"not null" example:"Sample Post Title"`
+	Slug      string    `json:"slug" gorm:"type:varchar(100);uniqueIndex" example:"sample-post-title"`
 	Content   string    `json:"content" gorm:"not null" example:"This is the content of the post"`
-	Author    string    `json:"author" gorm:"not null" example:"John Doe"`
-	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime" example:"2023-01-01T00:00:00Z"`
+	Author    string    `json:"author" gorm:"not null;index" example:"John Doe"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime;index:idx_posts_created_at_id,priority:1" example:"2023-01-01T00:00:00Z"`
 	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime" example:"2023-01-01T00:00:00Z"`
+	Version   int       `json:"version" gorm:"not null;default:1" example:"1"`
+	// Status defaults to published in the database so rows that predate the
+	// lifecycle stay visible; new posts always set it explicitly
+	Status PostStatus `json:"status" gorm:"type:varchar(20);not null;default:'published';index" enums:"draft,scheduled,published,archived" example:"published"`
+	// PublishAt is when a scheduled post goes live, or when a published post went live
+	PublishAt *time.Time `json:"publish_at,omitempty" gorm:"index" example:"2023-01-01T09:00:00Z"`
+	Tags      []Tag      `json:"tags" gorm:"many2many:post_tags;constraint:OnDelete:CASCADE" swaggertype:"array,string" example:"go,kubernetes"`
+	// DeletedAt is set when the post is moved to the trash
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index" swaggertype:"string" format:"date-time" example:"2023-01-02T00:00:00Z"`
 }
 
 func (p *Post) BeforeCreate(tx *gorm.DB) error {
@@ -27,22 +37,50 @@ type CreatePostRequest struct {
 	Title   string `json:"title" binding:"required" example:"New Post Title"`
 	Content string `json:"content" binding:"required" example:"Post content goes here"`
 	Author  string `json:"author" binding:"required" example:"Jane Doe"`
+	// Status is draft unless set to published; a future PublishAt schedules the post
+	Status    PostStatus `json:"status,omitempty" binding:"omitempty,oneof=draft published" enums:"draft,published" example:"draft"`
+	PublishAt *time.Time `json:"publish_at,omitempty" example:"2023-01-01T09:00:00Z"`
+	Tags      []string   `json:"tags,omitempty" example:"go,kubernetes"`
+}
+
+// Validate checks the combination of status and publish time, and the tags
+func (r CreatePostRequest) Validate() error {
+	if r.Status == PostStatusDraft && r.PublishAt != nil {
+		return errDraftWithPublishAt
+	}
+	return ValidateTags(NormalizeTags(r.Tags))
 }
 
 type UpdatePostRequest struct {
 	Title   string `json:"title,omitempty" example:"Updated Post Title"`
 	Content string `json:"content,omitempty" example:"Updated post content"`
 	Author  string `json:"author,omitempty" example:"Updated Author"`
+	// Editor is recorded on the revision written by the update and defaults to the author
+	Editor string `json:"editor,omitempty" example:"Jane Doe"`
+	// Tags replaces the post's tags when present; an empty array removes them all
+	Tags []string `json:"tags,omitempty" example:"go,kubernetes"`
+}
+
+// Validate checks the tags of the update
+func (r UpdatePostRequest) Validate() error {
+	return ValidateTags(NormalizeTags(r.Tags))
 }
 
 func NewPost(req CreatePostRequest) *Post {
 	now := time.Now()
-	return &Post{
+	post := &Post{
 		ID:        uuid.New().String(),
 		Title:     req.Title,
 		Content:   req.Content,
 		Author:    req.Author,
 		CreatedAt: now,
 		UpdatedAt: now,
+		Version:   1,
+		Status:    PostStatusDraft,
+		Tags:      NewTags(NormalizeTags(req.Tags)),
+	}
+	if req.Status == PostStatusPublished || req.PublishAt != nil {
+		post.Status, post.PublishAt = resolvePublication(req.PublishAt, now)
 	}
+	return post
 }
\ No newline at end of file
@@ -0,0 +1,264 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrInvalidSort = errors.New("invalid sort")
+
+// DefaultPostSort is applied when a query does not specify a sort
+var DefaultPostSort = []SortField{{Field: "created_at", Desc: true}}
+
+// SortField is a single entry of a sort specification such as "-created_at"
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+func (f SortField) String() string {
+	if f.Desc {
+		return "-" + f.Field
+	}
+	return f.Field
+}
+
+// Column returns the database column of a sortable field
+func (f SortField) Column() string {
+	return sortablePostFields[f.Field].column
+}
+
+// postSortKey describes how a sortable field is ordered in SQL and in memory
+type postSortKey struct {
+	column string
+	// encode renders the field of a post as a cursor value
+	encode func(p *Post) string
+	// decode parses a cursor value into a SQL argument
+	decode func(s string) (interface{}, error)
+	// compare orders the field of a post against a decoded cursor value
+	compare func(p *Post, v interface{}) int
+	// comparePosts orders two posts by the field
+	comparePosts func(a, b *Post) int
+}
+
+func stringSortKey(column string, get func(p *Post) string) postSortKey {
+	return postSortKey{
+		column: column,
+		encode: get,
+		decode: func(s string) (interface{}, error) { return s, nil },
+		compare: func(p *Post, v interface{}) int {
+			return strings.Compare(get(p), v.(string))
+		},
+		comparePosts: func(a, b *Post) int {
+			return strings.Compare(get(a), get(b))
+		},
+	}
+}
+
+func timeSortKey(column string, get func(p *Post) time.Time) postSortKey {
+	return postSortKey{
+		column: column,
+		encode: func(p *Post) string { return get(p).Format(time.RFC3339Nano) },
+		decode: func(s string) (interface{}, error) { return time.Parse(time.RFC3339Nano, s) },
+		compare: func(p *Post, v interface{}) int {
+			return get(p).Compare(v.(time.Time))
+		},
+		comparePosts: func(a, b *Post) int {
+			return get(a).Compare(get(b))
+		},
+	}
+}
+
+// sortablePostFields is the allowlist of fields posts can be sorted by
+var sortablePostFields = map[string]postSortKey{
+	"title":      stringSortKey("title", func(p *Post) string { return p.Title }),
+	"author":     stringSortKey("author", func(p *Post) string { return p.Author }),
+	"created_at": timeSortKey("created_at", func(p *Post) time.Time { return p.CreatedAt }),
+	"updated_at": timeSortKey("updated_at", func(p *Post) time.Time { return p.UpdatedAt }),
+	"deleted_at": timeSortKey("deleted_at", func(p *Post) time.Time { return p.DeletedAt.Time }),
+}
+
+// trashOnlySortFields are only set on posts in the trash
+var trashOnlySortFields = map[string]bool{"deleted_at": true}
+
+// ParseSort parses a comma separated sort specification such as
+// "title,-created_at". A leading "-" sorts the field in descending order.
+func ParseSort(spec string) ([]SortField, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool)
+	var fields []SortField
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		field := SortField{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+
+		if _, ok := sortablePostFields[field.Field]; !ok {
+			return nil, fmt.Errorf("%w: cannot sort by %q", ErrInvalidSort, field.Field)
+		}
+		if seen[field.Field] {
+			return nil, fmt.Errorf("%w: %q is listed more than once", ErrInvalidSort, field.Field)
+		}
+		seen[field.Field] = true
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// PostQuery describes a filtered, sorted and paginated post listing. It is
+// built by the handler and passed unchanged to the repository.
+type PostQuery struct {
+	Author        string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedSince  *time.Time
+	Sort          []SortField
+	Page          PageRequest
+	// Tags restricts the listing to posts carrying any or all of the tags
+	Tags     []string
+	TagMatch TagMatch
+	// Status restricts the listing to posts in that status, if set
+	Status PostStatus
+	// Deleted selects posts in the trash instead of live posts
+	Deleted bool
+}
+
+// OrderBy returns the effective sort, falling back to DefaultPostSort
+func (q PostQuery) OrderBy() []SortField {
+	if len(q.Sort) == 0 {
+		return DefaultPostSort
+	}
+	return q.Sort
+}
+
+// IDDesc reports the direction of the ID tiebreaker, which follows the last sort field
+func (q PostQuery) IDDesc() bool {
+	order := q.OrderBy()
+	return order[len(order)-1].Desc
+}
+
+// SortKey returns the canonical form of the effective sort
+func (q PostQuery) SortKey() string {
+	parts := make([]string, 0, len(q.OrderBy()))
+	for _, f := range q.OrderBy() {
+		parts = append(parts, f.String())
+	}
+	return strings.Join(parts, ",")
+}
+
+// Validate checks the sort against the allowlist and makes sure the cursor,
+// if any, was issued for the same sort
+func (q PostQuery) Validate() error {
+	for _, f := range q.OrderBy() {
+		if _, ok := sortablePostFields[f.Field]; !ok {
+			return fmt.Errorf("%w: cannot sort by %q", ErrInvalidSort, f.Field)
+		}
+		if trashOnlySortFields[f.Field] && !q.Deleted {
+			return fmt.Errorf("%w: %q can only be used in the trash", ErrInvalidSort, f.Field)
+		}
+	}
+	if q.CreatedAfter != nil && q.CreatedBefore != nil && !q.CreatedAfter.Before(*q.CreatedBefore) {
+		return errors.New("created_after must be before created_before")
+	}
+	_, err := q.CursorValues()
+	return err
+}
+
+// CursorValues decodes the cursor sort key values into typed arguments, in
+// OrderBy order. It returns nil when the query has no cursor.
+func (q PostQuery) CursorValues() ([]interface{}, error) {
+	c := q.Page.Cursor
+	if c == nil {
+		return nil, nil
+	}
+
+	order := q.OrderBy()
+	if c.Sort != q.SortKey() || len(c.Values) != len(order) {
+		return nil, fmt.Errorf("%w: cursor does not match sort %q", ErrInvalidCursor, q.SortKey())
+	}
+
+	values := make([]interface{}, len(order))
+	for i, f := range order {
+		v, err := sortablePostFields[f.Field].decode(c.Values[i])
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// Matches reports whether a post satisfies the query filters
+func (q PostQuery) Matches(p *Post) bool {
+	if p.DeletedAt.Valid != q.Deleted {
+		return false
+	}
+	if q.Status != "" && p.Status != q.Status {
+		return false
+	}
+	if q.Author != "" && p.Author != q.Author {
+		return false
+	}
+	if q.CreatedAfter != nil && !p.CreatedAt.After(*q.CreatedAfter) {
+		return false
+	}
+	if q.CreatedBefore != nil && !p.CreatedAt.Before(*q.CreatedBefore) {
+		return false
+	}
+	if q.UpdatedSince != nil && p.UpdatedAt.Before(*q.UpdatedSince) {
+		return false
+	}
+	if len(q.Tags) > 0 {
+		matched := 0
+		for _, name := range p.TagNames() {
+			for _, tag := range q.Tags {
+				if name == tag {
+					matched++
+				}
+			}
+		}
+		if matched == 0 || (q.TagMatch == TagMatchAll && matched < len(q.Tags)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Less orders two posts according to the query sort and ID tiebreaker
+func (q PostQuery) Less(a, b *Post) bool {
+	for _, f := range q.OrderBy() {
+		if cmp := sortablePostFields[f.Field].comparePosts(a, b); cmp != 0 {
+			return (cmp < 0) != f.Desc
+		}
+	}
+	if q.IDDesc() {
+		return a.ID > b.ID
+	}
+	return a.ID < b.ID
+}
+
+// IsAfterCursor reports whether a post sorts strictly after the cursor
+// position. values must come from CursorValues.
+func (q PostQuery) IsAfterCursor(p *Post, values []interface{}) bool {
+	for i, f := range q.OrderBy() {
+		if cmp := sortablePostFields[f.Field].compare(p, values[i]); cmp != 0 {
+			return (cmp > 0) != f.Desc
+		}
+	}
+	if q.IDDesc() {
+		return p.ID < q.Page.Cursor.ID
+	}
+	return p.ID > q.Page.Cursor.ID
+}
+
+func (q PostQuery) cursorAt(p *Post) Cursor {
+	order := q.OrderBy()
+	c := Cursor{Sort: q.SortKey(), Values: make([]string, len(order)), ID: p.ID}
+	for i, f := range order {
+		c.Values[i] = sortablePostFields[f.Field].encode(p)
+	}
+	return c
+}
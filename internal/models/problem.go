@@ -0,0 +1,23 @@
+package models
+
+// ProblemContentType is the media type of Problem responses
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details response, returned for every failed request
+type Problem struct {
+	Type     string `json:"type" example:"about:blank"`
+	Title    string `json:"title" example:"Not Found"`
+	Status   int    `json:"status" example:"404"`
+	Detail   string `json:"detail,omitempty" example:"post not found"`
+	Instance string `json:"instance,omitempty" example:"/api/v1/posts/123e4567-e89b-12d3-a456-426614174000"`
+	// RequestID matches the X-Request-ID response header
+	RequestID string `json:"request_id,omitempty" example:"4f9c2a7e-3b1d-4c8e-9f0a-6d2e1b7c5a3f"`
+	// Errors lists the rejected fields of a validation problem
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError explains why a single request field or parameter was rejected
+type FieldError struct {
+	Field   string `json:"field" example:"title"`
+	Message string `json:"message" example:"is required"`
+}
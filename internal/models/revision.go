@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"postService/pkg/utils"
+)
+
+// PostRevision is an immutable snapshot of a post written on every create and
+// update. Revision numbers match the post version the write produced.
+type PostRevision struct {
+	ID        uint      `json:"-" gorm:"primaryKey"`
+	PostID    string    `json:"post_id" gorm:"type:uuid;not null;uniqueIndex:idx_post_revisions_post_revision,priority:1" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Revision  int       `json:"revision" gorm:"not null;uniqueIndex:idx_post_revisions_post_revision,priority:2" example:"2"`
+	Title     string    `json:"title" gorm:"not null" example:"Sample Post Title"`
+	Content   string    `json:"content" gorm:"not null" example:"This is the content of the post"`
+	Author    string    `json:"author" gorm:"not null" example:"John Doe"`
+	Editor    string    `json:"editor" gorm:"not null" example:"Jane Doe"`
+	CreatedAt time.Time `json:"created_at" example:"2023-01-01T00:00:00Z"`
+
+	// Post only exists so that purging a post cascades to its revisions
+	Post *Post `json:"-" gorm:"constraint:OnDelete:CASCADE" swaggerignore:"true"`
+}
+
+// NewPostRevision snapshots the current state of a post. The editor defaults
+// to the post author when it is not known.
+func NewPostRevision(post *Post, editor string) *PostRevision {
+	if editor == "" {
+		editor = post.Author
+	}
+	return &PostRevision{
+		PostID:    post.ID,
+		Revision:  post.Version,
+		Title:     post.Title,
+		Content:   post.Content,
+		Author:    post.Author,
+		Editor:    editor,
+		CreatedAt: post.UpdatedAt,
+	}
+}
+
+type RestoreRevisionRequest struct {
+	Editor string `json:"editor,omitempty" example:"Jane Doe"`
+}
+
+// RevisionDiff is the line-level difference between two revisions of a post
+type RevisionDiff struct {
+	PostID  string           `json:"post_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	From    int              `json:"from" example:"1"`
+	To      int              `json:"to" example:"2"`
+	Title   []utils.DiffLine `json:"title"`
+	Author  []utils.DiffLine `json:"author"`
+	Content []utils.DiffLine `json:"content"`
+}
+
+// NewRevisionDiff diffs every field of two revisions of the same post
+func NewRevisionDiff(from, to *PostRevision) *RevisionDiff {
+	differ := utils.NewDiffHelper()
+	return &RevisionDiff{
+		PostID:  from.PostID,
+		From:    from.Revision,
+		To:      to.Revision,
+		Title:   differ.Lines(from.Title, to.Title),
+		Author:  differ.Lines(from.Author, to.Author),
+		Content: differ.Lines(from.Content, to.Content),
+	}
+}
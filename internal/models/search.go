@@ -0,0 +1,54 @@
+package models
+
+// HighlightStart and HighlightStop wrap matched terms in search highlights
+const (
+	HighlightStart = "<mark>"
+	HighlightStop  = "</mark>"
+)
+
+type SearchQuery struct {
+	Query  string
+	Limit  int
+	Offset int
+}
+
+type PostSearchHit struct {
+	Post             *Post   `json:"post"`
+	Rank             float64 `json:"rank" example:"0.0759"`
+	TitleHighlight   string  `json:"title_highlight" example:"Getting started with <mark>Go</mark>"`
+	ContentHighlight string  `json:"content_highlight" example:"... writing your first <mark>Go</mark> program ..."`
+}
+
+type SearchPageInfo struct {
+	Limit   int  `json:"limit" example:"20"`
+	Offset  int  `json:"offset" example:"0"`
+	Count   int  `json:"count" example:"20"`
+	HasMore bool `json:"has_more" example:"true"`
+}
+
+type PostSearchResults struct {
+	Query string           `json:"query" example:"go tips"`
+	Data  []*PostSearchHit `json:"data"`
+	Page  SearchPageInfo   `json:"page"`
+}
+
+// NewPostSearchResults builds a result page from up to Limit+1 ranked hits.
+// The extra hit, if present, only signals that another page exists.
+func NewPostSearchResults(hits []*PostSearchHit, query SearchQuery) *PostSearchResults {
+	results := &PostSearchResults{
+		Query: query.Query,
+		Data:  hits,
+		Page:  SearchPageInfo{Limit: query.Limit, Offset: query.Offset},
+	}
+
+	if len(hits) > query.Limit {
+		results.Data = hits[:query.Limit]
+		results.Page.HasMore = true
+	}
+	if results.Data == nil {
+		results.Data = []*PostSearchHit{}
+	}
+	results.Page.Count = len(results.Data)
+
+	return results
+}
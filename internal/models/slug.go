@@ -0,0 +1,68 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"postService/pkg/utils"
+)
+
+const (
+	// MaxSlugBaseLength caps the part of a slug derived from the title, leaving
+	// room for a collision suffix
+	MaxSlugBaseLength = 80
+	// fallbackSlug is used for titles without a single sluggable character
+	fallbackSlug = "post"
+)
+
+// PostSlug records every slug a post has had. Slugs are never handed to
+// another post, so links to an old slug keep redirecting to the same post.
+type PostSlug struct {
+	Slug      string `gorm:"type:varchar(100);primaryKey"`
+	PostID    string `gorm:"type:uuid;not null;index"`
+	CreatedAt time.Time
+
+	// Post only exists so that purging a post cascades to its slug history
+	Post *Post `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+// SlugBase derives the collision-free part of a slug from a post title
+func SlugBase(title string) string {
+	base := utils.NewStringHelper().Slugify(title)
+	if len(base) > MaxSlugBaseLength {
+		base = base[:MaxSlugBaseLength]
+		// Cut at a word boundary when there is one
+		if i := strings.LastIndexByte(base, '-'); i > 0 {
+			base = base[:i]
+		}
+		base = strings.TrimSuffix(base, "-")
+	}
+	if base == "" {
+		return fallbackSlug
+	}
+	return base
+}
+
+// SlugCandidate returns the n-th slug to try for a base, starting at 1:
+// "base", then "base-2", "base-3" and so on
+func SlugCandidate(base string, n int) string {
+	if n <= 1 {
+		return base
+	}
+	return base + "-" + strconv.Itoa(n)
+}
+
+// SlugMatchesBase reports whether slug is one of the candidates for base, in
+// which case a title change that keeps the base does not need a new slug
+func SlugMatchesBase(slug, base string) bool {
+	if slug == base {
+		return true
+	}
+	suffix, ok := strings.CutPrefix(slug, base+"-")
+	if !ok {
+		return false
+	}
+	n, err := strconv.Atoi(suffix)
+	return err == nil && n > 1 && strconv.Itoa(n) == suffix
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSlugBase(t *testing.T) {
+	tests := []struct {
+		name, title, want string
+	}{
+		{"words", "Hello, World!", "hello-world"},
+		{"digits", "Go 1.23 Released", "go-1-23-released"},
+		{"surrounding separators", "  -- Hello -- ", "hello"},
+		{"accents", "Crème Brûlée à la carte", "creme-brulee-a-la-carte"},
+		{"special letters", "Straße Œuvre Łódź", "strasse-oeuvre-lodz"},
+		{"cyrillic", "Привет, мир", "privet-mir"},
+		{"silent signs", "Объект", "obekt"},
+		{"greek", "Ωμέγα", "omega"},
+		{"compatibility forms", "ﬁne ＦＵＬＬ", "fine-full"},
+		{"empty", "", fallbackSlug},
+		{"symbols only", "!!! ??? ...", fallbackSlug},
+		{"untransliterated script", "你好", fallbackSlug},
+		{"mixed script", "Go 你好 Gophers", "go-gophers"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SlugBase(tt.title); got != tt.want {
+				t.Errorf("SlugBase(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlugBaseTruncation(t *testing.T) {
+	tests := []struct {
+		name, title, want string
+	}{
+		{"cut at a word boundary", strings.Repeat("word ", 30), strings.TrimSuffix(strings.Repeat("word-", 16), "-")},
+		{"one long word", strings.Repeat("a", 120), strings.Repeat("a", MaxSlugBaseLength)},
+		{"boundary right at the limit", strings.Repeat("a", MaxSlugBaseLength) + " tail", strings.Repeat("a", MaxSlugBaseLength)},
+		{"exactly the limit", strings.Repeat("b", MaxSlugBaseLength), strings.Repeat("b", MaxSlugBaseLength)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SlugBase(tt.title)
+			if got != tt.want {
+				t.Errorf("SlugBase = %q (%d), want %q (%d)", got, len(got), tt.want, len(tt.want))
+			}
+			// The longest suffix still fits the slug column
+			if len(SlugCandidate(got, 9999)) > 100 {
+				t.Errorf("slug %q with a suffix exceeds 100 characters", got)
+			}
+		})
+	}
+}
+
+func TestSlugCandidate(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, "hello"},
+		{1, "hello"},
+		{2, "hello-2"},
+		{10, "hello-10"},
+	}
+	for _, tt := range tests {
+		if got := SlugCandidate("hello", tt.n); got != tt.want {
+			t.Errorf("SlugCandidate(hello, %d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestSlugMatchesBase(t *testing.T) {
+	tests := []struct {
+		slug string
+		want bool
+	}{
+		{"go", true},
+		{"go-2", true},
+		{"go-17", true},
+		{"go-1", false},
+		{"go-0", false},
+		{"go-02", false},
+		{"go-x", false},
+		{"go-tips", false},
+		{"go-tips-2", false},
+		{"gopher", false},
+	}
+	for _, tt := range tests {
+		if got := SlugMatchesBase(tt.slug, "go"); got != tt.want {
+			t.Errorf("SlugMatchesBase(%q, go) = %v, want %v", tt.slug, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+type PostStatus string
+
+const (
+	PostStatusDraft     PostStatus = "draft"
+	PostStatusScheduled PostStatus = "scheduled"
+	PostStatusPublished PostStatus = "published"
+	PostStatusArchived  PostStatus = "archived"
+)
+
+// postTransitions lists, for every target status, the statuses a post may
+// move to it from
+var postTransitions = map[PostStatus][]PostStatus{
+	PostStatusDraft:     {PostStatusScheduled, PostStatusPublished},
+	PostStatusScheduled: {PostStatusDraft, PostStatusScheduled, PostStatusArchived},
+	PostStatusPublished: {PostStatusDraft, PostStatusScheduled, PostStatusArchived},
+	PostStatusArchived:  {PostStatusDraft, PostStatusScheduled, PostStatusPublished},
+}
+
+// Valid reports whether s is one of the known statuses
+func (s PostStatus) Valid() bool {
+	_, ok := postTransitions[s]
+	return ok
+}
+
+// TransitionSources returns the statuses a post may move to target from
+func TransitionSources(target PostStatus) []PostStatus {
+	return postTransitions[target]
+}
+
+// CanTransitionTo reports whether a post in status s may move to target
+func (s PostStatus) CanTransitionTo(target PostStatus) bool {
+	for _, from := range postTransitions[target] {
+		if from == s {
+			return true
+		}
+	}
+	return false
+}
+
+type PublishPostRequest struct {
+	// PublishAt schedules the post instead of publishing it immediately
+	PublishAt *time.Time `json:"publish_at,omitempty" example:"2023-01-01T09:00:00Z"`
+}
+
+// resolvePublication decides whether a post publishes now or is scheduled for publishAt
+func resolvePublication(publishAt *time.Time, now time.Time) (PostStatus, *time.Time) {
+	if publishAt != nil && publishAt.After(now) {
+		return PostStatusScheduled, publishAt
+	}
+	return PostStatusPublished, &now
+}
+
+// PublishTarget returns the status and publish time a publish request leads to
+func (r PublishPostRequest) PublishTarget(now time.Time) (PostStatus, *time.Time) {
+	return resolvePublication(r.PublishAt, now)
+}
+
+var errDraftWithPublishAt = errors.New("publish_at cannot be combined with status draft")
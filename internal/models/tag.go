@@ -0,0 +1,91 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	MaxTagsPerPost = 20
+	MaxTagLength   = 50
+)
+
+// Tag is serialised as its bare name, so posts carry tags as a string array
+type Tag struct {
+	ID   uint   `gorm:"primaryKey"`
+	Name string `gorm:"size:50;not null;uniqueIndex"`
+}
+
+func (t Tag) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.Name)
+}
+
+func (t *Tag) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &t.Name)
+}
+
+// TagCount is a tag with the number of published posts carrying it
+type TagCount struct {
+	Name  string `json:"name" example:"go"`
+	Count int64  `json:"count" example:"42"`
+}
+
+// TagMatch selects whether a post needs any or all of the requested tags
+type TagMatch string
+
+const (
+	TagMatchAny TagMatch = "any"
+	TagMatchAll TagMatch = "all"
+)
+
+// NormalizeTags lowercases and trims tag names, dropping empty names and
+// duplicates while keeping the first-seen order
+func NormalizeTags(raw []string) []string {
+	if raw == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(raw))
+	tags := make([]string, 0, len(raw))
+	for _, name := range raw {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		tags = append(tags, name)
+	}
+	return tags
+}
+
+// ValidateTags checks normalised tag names against the tag limits
+func ValidateTags(tags []string) error {
+	if len(tags) > MaxTagsPerPost {
+		return fmt.Errorf("a post can have at most %d tags", MaxTagsPerPost)
+	}
+	for _, name := range tags {
+		if len([]rune(name)) > MaxTagLength {
+			return fmt.Errorf("tag %q is longer than %d characters", name, MaxTagLength)
+		}
+	}
+	return nil
+}
+
+// NewTags wraps tag names in Tag values
+func NewTags(names []string) []Tag {
+	tags := make([]Tag, 0, len(names))
+	for _, name := range names {
+		tags = append(tags, Tag{Name: name})
+	}
+	return tags
+}
+
+// TagNames returns the names of a post's tags
+func (p *Post) TagNames() []string {
+	names := make([]string, 0, len(p.Tags))
+	for _, tag := range p.Tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"postService/internal/models"
+)
+
+type CommentRepository interface {
+	Create(ctx context.Context, comment *models.Comment) error
+	// GetByID returns a comment on a post, including tombstones
+	GetByID(ctx context.Context, postID, id string) (*models.Comment, error)
+	// ListThreads returns a page of top-level comments on a post, oldest
+	// first, with every reply in their threads nested underneath
+	ListThreads(ctx context.Context, query models.CommentQuery) (*models.CommentPage, error)
+	Update(ctx context.Context, postID, id string, body string) (*models.Comment, error)
+	// Delete turns a comment into a tombstone so its replies keep their thread
+	Delete(ctx context.Context, postID, id string) error
+}
+
+// InMemoryCommentRepository keeps comments apart from the posts they belong
+// to. Comments of a purged post stay behind, but are unreachable because
+// every lookup goes through a post ID that no longer resolves.
+type InMemoryCommentRepository struct {
+	comments map[string]*models.Comment
+	mutex    sync.RWMutex
+}
+
+func NewInMemoryCommentRepository() *InMemoryCommentRepository {
+	return &InMemoryCommentRepository{
+		comments: make(map[string]*models.Comment),
+	}
+}
+
+func (r *InMemoryCommentRepository) Create(ctx context.Context, comment *models.Comment) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.comments[comment.ID] = cloneComment(comment)
+	return nil
+}
+
+func (r *InMemoryCommentRepository) GetByID(ctx context.Context, postID, id string) (*models.Comment, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	comment, exists := r.comments[id]
+	if !exists || comment.PostID != postID {
+		return nil, errCommentNotFound
+	}
+	return cloneComment(comment), nil
+}
+
+func (r *InMemoryCommentRepository) ListThreads(ctx context.Context, query models.CommentQuery) (*models.CommentPage, error) {
+	cursor, err := query.CursorTime()
+	if err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var roots []*models.Comment
+	for _, comment := range r.comments {
+		if comment.PostID != query.PostID || comment.ParentID != nil {
+			continue
+		}
+		if cursor != nil && !query.IsAfterCursor(comment, *cursor) {
+			continue
+		}
+		roots = append(roots, cloneComment(comment))
+	}
+
+	sort.Slice(roots, func(i, j int) bool {
+		return models.LessComment(roots[i], roots[j])
+	})
+	if len(roots) > query.Page.Limit+1 {
+		roots = roots[:query.Page.Limit+1]
+	}
+
+	threads := make(map[string]bool, len(roots))
+	for _, root := range roots {
+		threads[root.ID] = true
+	}
+	var replies []*models.Comment
+	for _, comment := range r.comments {
+		if comment.RootID != nil && threads[*comment.RootID] {
+			replies = append(replies, cloneComment(comment))
+		}
+	}
+
+	return models.NewCommentPage(roots, replies, query), nil
+}
+
+func (r *InMemoryCommentRepository) Update(ctx context.Context, postID, id string, body string) (*models.Comment, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	comment, exists := r.comments[id]
+	if !exists || comment.PostID != postID || comment.DeletedAt != nil {
+		return nil, errCommentNotFound
+	}
+
+	comment.Body = body
+	comment.UpdatedAt = time.Now()
+	return cloneComment(comment), nil
+}
+
+func (r *InMemoryCommentRepository) Delete(ctx context.Context, postID, id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	comment, exists := r.comments[id]
+	if !exists || comment.PostID != postID || comment.DeletedAt != nil {
+		return errCommentNotFound
+	}
+
+	comment.Tombstone(time.Now())
+	return nil
+}
+
+// cloneComment copies a stored comment so callers can nest replies into it
+// without touching shared state
+func cloneComment(comment *models.Comment) *models.Comment {
+	c := *comment
+	c.Replies = nil
+	return &c
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// Error kinds. Every error returned by a repository either is or wraps one of
+// them, except for programming errors, so callers never match on messages.
+var (
+	ErrNotFound    = errors.New("not found")
+	ErrConflict    = errors.New("conflict")
+	ErrValidation  = errors.New("validation failed")
+	ErrUnavailable = errors.New("storage unavailable")
+)
+
+// DomainError is an error of one of the kinds above with a message of its own.
+// Err optionally keeps the underlying cause for logging.
+type DomainError struct {
+	Kind    error
+	Message string
+	Err     error
+}
+
+func (e *DomainError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *DomainError) Unwrap() []error {
+	if e.Err != nil {
+		return []error{e.Kind, e.Err}
+	}
+	return []error{e.Kind}
+}
+
+var (
+	// ErrVersionMismatch is returned when a conditional write finds the post at a
+	// different version than the caller expected
+	ErrVersionMismatch = &DomainError{Kind: ErrConflict, Message: "post version mismatch"}
+
+	// ErrInvalidTransition is returned when a post cannot move from its current
+	// status to the requested one
+	ErrInvalidTransition = &DomainError{Kind: ErrConflict, Message: "invalid status transition"}
+
+	errPostNotFound        = &DomainError{Kind: ErrNotFound, Message: "post not found"}
+	errTrashedPostNotFound = &DomainError{Kind: ErrNotFound, Message: "post not found in trash"}
+	errRevisionNotFound    = &DomainError{Kind: ErrNotFound, Message: "revision not found"}
+	errCommentNotFound     = &DomainError{Kind: ErrNotFound, Message: "comment not found"}
+)
+
+// translateError maps database driver errors onto the error kinds. notFound
+// replaces gorm.ErrRecordNotFound so the caller can say what was missing.
+func translateError(err error, notFound error) error {
+	if err == nil {
+		return nil
+	}
+
+	var domainErr *DomainError
+	if errors.As(err, &domainErr) {
+		return err
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return notFound
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == "23505":
+			return &DomainError{Kind: ErrConflict, Message: "duplicate value", Err: err}
+		case pgErr.Code == "40001" || pgErr.Code == "40P01":
+			return &DomainError{Kind: ErrConflict, Message: "concurrent update, retry the request", Err: err}
+		case pgErr.Code == "23503" || pgErr.Code == "23502" || pgErr.Code == "23514":
+			return &DomainError{Kind: ErrValidation, Message: "constraint violated", Err: err}
+		case strings.HasPrefix(pgErr.Code, "22"):
+			// Data exceptions, such as a malformed UUID in the path
+			return &DomainError{Kind: ErrValidation, Message: "invalid value", Err: err}
+		case strings.HasPrefix(pgErr.Code, "08") || strings.HasPrefix(pgErr.Code, "53") ||
+			strings.HasPrefix(pgErr.Code, "57") || pgErr.Code == "25006":
+			// Connection failures, exhausted resources, shutdowns and cancellations,
+			// and writes against a read-only standby
+			return &DomainError{Kind: ErrUnavailable, Message: "database unavailable", Err: err}
+		}
+		return err
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		// The request ran out of time or its client went away mid-query
+		return &DomainError{Kind: ErrUnavailable, Message: "query cancelled before completing", Err: err}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, driver.ErrBadConn) ||
+		pgconn.Timeout(err) || pgconn.SafeToRetry(err) {
+		return &DomainError{Kind: ErrUnavailable, Message: "database unavailable", Err: err}
+	}
+	return err
+}
@@ -1,73 +1,185 @@
 package repository
 
 import (
-	"errors"
+	"context"
+	"sort"
+	"strings"
 	"sync"
 	"time"
+	"unicode"
 
+	"gorm.io/gorm"
 	"postService/internal/models"
 )
 
 type PostRepository interface {
-	Create(post *models.Post) error
-	GetByID(id string) (*models.Post, error)
-	GetAll() ([]*models.Post, error)
-	Update(id string, req models.UpdatePostRequest) (*models.Post, error)
-	Delete(id string) error
+	Create(ctx context.Context, post *models.Post) error
+	GetByID(ctx context.Context, id string) (*models.Post, error)
+	// GetBySlug finds a post by its current slug or any slug it had before
+	GetBySlug(ctx context.Context, slug string) (*models.Post, error)
+	Find(ctx context.Context, query models.PostQuery) (*models.PostPage, error)
+	Search(ctx context.Context, query models.SearchQuery) (*models.PostSearchResults, error)
+	// Update and Delete only apply when the post is at expectedVersion and
+	// return ErrVersionMismatch otherwise. An expectedVersion of 0 skips the check.
+	Update(ctx context.Context, id string, req models.UpdatePostRequest, expectedVersion int) (*models.Post, error)
+	// Delete moves the post to the trash, from where Restore brings it back
+	Delete(ctx context.Context, id string, expectedVersion int) error
+	Restore(ctx context.Context, id string) (*models.Post, error)
+	// Purge permanently removes a post that is already in the trash
+	Purge(ctx context.Context, id string) error
+	// ListRevisions returns the revisions of a post, newest first. Create and
+	// Update record a revision in the same write as the post itself.
+	ListRevisions(ctx context.Context, postID string) ([]*models.PostRevision, error)
+	GetRevision(ctx context.Context, postID string, revision int) (*models.PostRevision, error)
+	// Transition moves a post to status if its current status allows it,
+	// returning ErrInvalidTransition otherwise
+	Transition(ctx context.Context, id string, status models.PostStatus, publishAt *time.Time, expectedVersion int) (*models.Post, error)
+	// PublishDue publishes every scheduled post whose publish time has passed
+	// and returns how many were published
+	PublishDue(ctx context.Context, now time.Time) (int64, error)
+	// ListTags returns tags by the number of published posts carrying them
+	ListTags(ctx context.Context, limit int) ([]models.TagCount, error)
 }
 
 type InMemoryPostRepository struct {
-	posts map[string]*models.Post
+	posts     map[string]*models.Post
+	revisions map[string][]*models.PostRevision
+	// slugs maps every slug ever assigned to the ID of its post
+	slugs map[string]string
 	mutex sync.RWMutex
 }
 
 func NewInMemoryPostRepository() *InMemoryPostRepository {
 	return &InMemoryPostRepository{
-		posts: make(map[string]*models.Post),
+		posts:     make(map[string]*models.Post),
+		revisions: make(map[string][]*models.PostRevision),
+		slugs:     make(map[string]string),
 	}
 }
 
-func (r *InMemoryPostRepository) Create(post *models.Post) error {
+func (r *InMemoryPostRepository) Create(ctx context.Context, post *models.Post) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	
+	r.assignSlug(post)
 	r.posts[post.ID] = post
+	r.revisions[post.ID] = append(r.revisions[post.ID], models.NewPostRevision(post, post.Author))
 	return nil
 }
 
-func (r *InMemoryPostRepository) GetByID(id string) (*models.Post, error) {
+func (r *InMemoryPostRepository) GetByID(ctx context.Context, id string) (*models.Post, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 	
 	post, exists := r.posts[id]
-	if !exists {
-		return nil, errors.New("post not found")
+	if !exists || post.DeletedAt.Valid {
+		return nil, errPostNotFound
 	}
 	return post, nil
 }
 
-func (r *InMemoryPostRepository) GetAll() ([]*models.Post, error) {
+func (r *InMemoryPostRepository) GetBySlug(ctx context.Context, slug string) (*models.Post, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	
+	post, exists := r.posts[r.slugs[slug]]
+	if !exists || post.DeletedAt.Valid {
+		return nil, errPostNotFound
+	}
+	return post, nil
+}
+
+func (r *InMemoryPostRepository) Find(ctx context.Context, query models.PostQuery) (*models.PostPage, error) {
+	cursor, err := query.CursorValues()
+	if err != nil {
+		return nil, err
+	}
+	
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 	
 	posts := make([]*models.Post, 0, len(r.posts))
 	for _, post := range r.posts {
+		if !query.Matches(post) {
+			continue
+		}
+		if cursor != nil && !query.IsAfterCursor(post, cursor) {
+			continue
+		}
 		posts = append(posts, post)
 	}
-	return posts, nil
+	
+	sort.Slice(posts, func(i, j int) bool {
+		return query.Less(posts[i], posts[j])
+	})
+	
+	if len(posts) > query.Page.Limit+1 {
+		posts = posts[:query.Page.Limit+1]
+	}
+	return models.NewPostPage(posts, query), nil
+}
+
+// Search is a naive stand-in for PostgreSQL full-text search: every query term
+// must occur in the title or content, and matches are scored by occurrence
+// count with title hits weighted like the 'A' label of search_vector.
+func (r *InMemoryPostRepository) Search(ctx context.Context, query models.SearchQuery) (*models.PostSearchResults, error) {
+	terms := searchTerms(query.Query)
+	
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	
+	var hits []*models.PostSearchHit
+	for _, post := range r.posts {
+		if post.DeletedAt.Valid || post.Status != models.PostStatusPublished {
+			continue
+		}
+		if rank := rankPost(post, terms); rank > 0 {
+			hits = append(hits, &models.PostSearchHit{Post: post, Rank: rank})
+		}
+	}
+	
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Rank != hits[j].Rank {
+			return hits[i].Rank > hits[j].Rank
+		}
+		if !hits[i].Post.CreatedAt.Equal(hits[j].Post.CreatedAt) {
+			return hits[i].Post.CreatedAt.After(hits[j].Post.CreatedAt)
+		}
+		return hits[i].Post.ID > hits[j].Post.ID
+	})
+	
+	if query.Offset >= len(hits) {
+		hits = nil
+	} else {
+		hits = hits[query.Offset:]
+	}
+	if len(hits) > query.Limit+1 {
+		hits = hits[:query.Limit+1]
+	}
+	for _, hit := range hits {
+		hit.TitleHighlight = highlightTerms(hit.Post.Title, terms)
+		hit.ContentHighlight = highlightTerms(contentSnippet(hit.Post.Content, terms), terms)
+	}
+	return models.NewPostSearchResults(hits, query), nil
 }
 
-func (r *InMemoryPostRepository) Update(id string, req models.UpdatePostRequest) (*models.Post, error) {
+func (r *InMemoryPostRepository) Update(ctx context.Context, id string, req models.UpdatePostRequest, expectedVersion int) (*models.Post, error) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	
 	post, exists := r.posts[id]
-	if !exists {
-		return nil, errors.New("post not found")
+	if !exists || post.DeletedAt.Valid {
+		return nil, errPostNotFound
+	}
+	if expectedVersion != 0 && post.Version != expectedVersion {
+		return nil, ErrVersionMismatch
 	}
 	
 	if req.Title != "" {
 		post.Title = req.Title
+		if !models.SlugMatchesBase(post.Slug, models.SlugBase(post.Title)) {
+			r.assignSlug(post)
+		}
 	}
 	if req.Content != "" {
 		post.Content = req.Content
@@ -75,21 +187,274 @@ func (r *InMemoryPostRepository) Update(id string, req models.UpdatePostRequest)
 	if req.Author != "" {
 		post.Author = req.Author
 	}
+	if req.Tags != nil {
+		post.Tags = models.NewTags(req.Tags)
+	}
 	post.UpdatedAt = time.Now()
+	post.Version++
 	
 	r.posts[id] = post
+	r.revisions[id] = append(r.revisions[id], models.NewPostRevision(post, req.Editor))
+	return post, nil
+}
+
+func (r *InMemoryPostRepository) Delete(ctx context.Context, id string, expectedVersion int) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	
+	post, exists := r.posts[id]
+	if !exists || post.DeletedAt.Valid {
+		return errPostNotFound
+	}
+	if expectedVersion != 0 && post.Version != expectedVersion {
+		return ErrVersionMismatch
+	}
+	
+	post.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	return nil
+}
+
+func (r *InMemoryPostRepository) Restore(ctx context.Context, id string) (*models.Post, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	
+	post, exists := r.posts[id]
+	if !exists || !post.DeletedAt.Valid {
+		return nil, errTrashedPostNotFound
+	}
+	
+	post.DeletedAt = gorm.DeletedAt{}
+	post.UpdatedAt = time.Now()
+	post.Version++
 	return post, nil
 }
 
-func (r *InMemoryPostRepository) Delete(id string) error {
+func (r *InMemoryPostRepository) Purge(ctx context.Context, id string) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	
-	_, exists := r.posts[id]
-	if !exists {
-		return errors.New("post not found")
+	post, exists := r.posts[id]
+	if !exists || !post.DeletedAt.Valid {
+		return errTrashedPostNotFound
 	}
 	
 	delete(r.posts, id)
+	delete(r.revisions, id)
+	for slug, postID := range r.slugs {
+		if postID == id {
+			delete(r.slugs, slug)
+		}
+	}
 	return nil
-}
\ No newline at end of file
+}
+
+func (r *InMemoryPostRepository) ListRevisions(ctx context.Context, postID string) ([]*models.PostRevision, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	
+	stored := r.revisions[postID]
+	revisions := make([]*models.PostRevision, 0, len(stored))
+	for i := len(stored) - 1; i >= 0; i-- {
+		revisions = append(revisions, stored[i])
+	}
+	return revisions, nil
+}
+
+func (r *InMemoryPostRepository) GetRevision(ctx context.Context, postID string, revision int) (*models.PostRevision, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	
+	for _, rev := range r.revisions[postID] {
+		if rev.Revision == revision {
+			return rev, nil
+		}
+	}
+	return nil, errRevisionNotFound
+}
+
+func (r *InMemoryPostRepository) Transition(ctx context.Context, id string, status models.PostStatus, publishAt *time.Time, expectedVersion int) (*models.Post, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	
+	post, exists := r.posts[id]
+	if !exists || post.DeletedAt.Valid {
+		return nil, errPostNotFound
+	}
+	if expectedVersion != 0 && post.Version != expectedVersion {
+		return nil, ErrVersionMismatch
+	}
+	if !post.Status.CanTransitionTo(status) {
+		return nil, ErrInvalidTransition
+	}
+	
+	post.Status = status
+	post.PublishAt = publishAt
+	post.UpdatedAt = time.Now()
+	post.Version++
+	return post, nil
+}
+
+func (r *InMemoryPostRepository) ListTags(ctx context.Context, limit int) ([]models.TagCount, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	
+	usage := make(map[string]int64)
+	for _, post := range r.posts {
+		if post.DeletedAt.Valid || post.Status != models.PostStatusPublished {
+			continue
+		}
+		for _, name := range post.TagNames() {
+			usage[name]++
+		}
+	}
+	
+	counts := make([]models.TagCount, 0, len(usage))
+	for name, count := range usage {
+		counts = append(counts, models.TagCount{Name: name, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Name < counts[j].Name
+	})
+	
+	if len(counts) > limit {
+		counts = counts[:limit]
+	}
+	return counts, nil
+}
+
+func (r *InMemoryPostRepository) PublishDue(ctx context.Context, now time.Time) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	
+	var published int64
+	for _, post := range r.posts {
+		if post.Status != models.PostStatusScheduled || post.DeletedAt.Valid || post.PublishAt == nil || post.PublishAt.After(now) {
+			continue
+		}
+		post.Status = models.PostStatusPublished
+		post.UpdatedAt = now
+		post.Version++
+		published++
+	}
+	return published, nil
+}
+
+// assignSlug gives a post the first free slug for its title, mirroring the
+// slug history of the Postgres repository. Callers must hold the write lock.
+func (r *InMemoryPostRepository) assignSlug(post *models.Post) {
+	base := models.SlugBase(post.Title)
+	for n := 1; ; n++ {
+		candidate := models.SlugCandidate(base, n)
+		if owner, taken := r.slugs[candidate]; !taken || owner == post.ID {
+			r.slugs[candidate] = post.ID
+			post.Slug = candidate
+			return
+		}
+	}
+}
+
+const (
+	titleWeight   = 1.0
+	contentWeight = 0.4
+	snippetRadius = 80
+)
+
+// searchTerms splits a search query into lowercase words
+func searchTerms(q string) []string {
+	return strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// rankPost scores a post against the search terms, returning 0 unless every
+// term occurs in the title or content
+func rankPost(post *models.Post, terms []string) float64 {
+	if len(terms) == 0 {
+		return 0
+	}
+	
+	title := strings.ToLower(post.Title)
+	content := strings.ToLower(post.Content)
+	
+	rank := 0.0
+	for _, term := range terms {
+		inTitle := strings.Count(title, term)
+		inContent := strings.Count(content, term)
+		if inTitle == 0 && inContent == 0 {
+			return 0
+		}
+		rank += titleWeight*float64(inTitle) + contentWeight*float64(inContent)
+	}
+	return rank
+}
+
+// contentSnippet returns the part of content around the first term match,
+// trimmed to word boundaries
+func contentSnippet(content string, terms []string) string {
+	lower := strings.ToLower(content)
+	first := -1
+	for _, term := range terms {
+		if i := strings.Index(lower, term); i >= 0 && (first < 0 || i < first) {
+			first = i
+		}
+	}
+	if first < 0 || len(content) <= 2*snippetRadius {
+		return content
+	}
+	
+	start, end := first-snippetRadius, first+snippetRadius
+	prefix, suffix := "... ", " ..."
+	if start <= 0 {
+		start, prefix = 0, ""
+	} else if i := strings.IndexByte(content[start:first], ' '); i >= 0 {
+		start += i + 1
+	}
+	if end >= len(content) {
+		end, suffix = len(content), ""
+	} else if i := strings.LastIndexByte(content[first:end], ' '); i > 0 {
+		end = first + i
+	}
+	return prefix + content[start:end] + suffix
+}
+
+// highlightTerms wraps every case-insensitive occurrence of the terms in
+// models.HighlightStart and models.HighlightStop
+func highlightTerms(text string, terms []string) string {
+	lower := strings.ToLower(text)
+	if len(lower) != len(text) {
+		// Lowercasing changed byte offsets; leave the text unmarked
+		return text
+	}
+	
+	marked := make([]bool, len(text))
+	for _, term := range terms {
+		for from := 0; ; {
+			i := strings.Index(lower[from:], term)
+			if i < 0 {
+				break
+			}
+			for j := from + i; j < from+i+len(term); j++ {
+				marked[j] = true
+			}
+			from += i + len(term)
+		}
+	}
+	
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		if marked[i] && (i == 0 || !marked[i-1]) {
+			b.WriteString(models.HighlightStart)
+		}
+		if !marked[i] && i > 0 && marked[i-1] {
+			b.WriteString(models.HighlightStop)
+		}
+		b.WriteByte(text[i])
+	}
+	if len(text) > 0 && marked[len(text)-1] {
+		b.WriteString(models.HighlightStop)
+	}
+	return b.String()
+}
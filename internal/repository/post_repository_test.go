@@ -0,0 +1,397 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"postService/internal/models"
+)
+
+// testPost builds a published post created at the given offset from a fixed
+// time. Times are whole microseconds, the precision Postgres keeps.
+func testPost(title, content string, created time.Duration) *models.Post {
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Add(created)
+	return &models.Post{
+		ID:        uuid.New().String(),
+		Title:     title,
+		Content:   content,
+		Author:    "ann",
+		CreatedAt: at,
+		UpdatedAt: at,
+		Version:   1,
+		Status:    models.PostStatusPublished,
+	}
+}
+
+func createPosts(t *testing.T, repo PostRepository, posts ...*models.Post) {
+	t.Helper()
+	for _, post := range posts {
+		if err := repo.Create(context.Background(), post); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func searchTitles(t *testing.T, repo PostRepository, query models.SearchQuery) ([]string, *models.PostSearchResults) {
+	t.Helper()
+	results, err := repo.Search(context.Background(), query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	titles := make([]string, 0, len(results.Data))
+	for _, hit := range results.Data {
+		titles = append(titles, hit.Post.Title)
+	}
+	return titles, results
+}
+
+func TestInMemorySearchRanking(t *testing.T) {
+	repo := NewInMemoryPostRepository()
+	draft := testPost("golang draft", "golang", 0)
+	draft.Status = models.PostStatusDraft
+	trashed := testPost("golang trashed", "golang", 0)
+	createPosts(t, repo,
+		testPost("cooking", "a golang mention in passing", 1*time.Hour),
+		testPost("golang tips", "nothing else", 2*time.Hour),
+		testPost("golang golang", "twice in the title", 3*time.Hour),
+		testPost("more golang tips", "nothing else", 4*time.Hour),
+		testPost("rust", "no match at all", 5*time.Hour),
+		draft,
+		trashed,
+	)
+	if err := repo.Delete(context.Background(), trashed.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		// Title matches outweigh content matches, repeated matches count, and
+		// equal ranks list the newest post first
+		{"weighted by field", "golang", []string{"golang golang", "more golang tips", "golang tips", "cooking"}},
+		{"every term required", "golang tips", []string{"more golang tips", "golang tips"}},
+		{"case insensitive", "GoLang TIPS", []string{"more golang tips", "golang tips"}},
+		{"no match", "haskell", []string{}},
+		{"punctuation only", "--", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			titles, _ := searchTitles(t, repo, models.SearchQuery{Query: tt.query, Limit: 10})
+			if strings.Join(titles, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("search %q = %q, want %q", tt.query, titles, tt.want)
+			}
+		})
+	}
+}
+
+func TestInMemorySearchPagination(t *testing.T) {
+	repo := NewInMemoryPostRepository()
+	for i := 0; i < 5; i++ {
+		createPosts(t, repo, testPost("golang", "content", time.Duration(i)*time.Hour))
+	}
+
+	_, first := searchTitles(t, repo, models.SearchQuery{Query: "golang", Limit: 2})
+	if first.Page.Count != 2 || !first.Page.HasMore {
+		t.Errorf("first page = %+v, want 2 hits and more", first.Page)
+	}
+	_, last := searchTitles(t, repo, models.SearchQuery{Query: "golang", Limit: 2, Offset: 4})
+	if last.Page.Count != 1 || last.Page.HasMore {
+		t.Errorf("last page = %+v, want 1 hit and no more", last.Page)
+	}
+	_, beyond := searchTitles(t, repo, models.SearchQuery{Query: "golang", Limit: 2, Offset: 10})
+	if beyond.Page.Count != 0 || beyond.Data == nil {
+		t.Errorf("page past the end = %+v, want an empty list", beyond)
+	}
+}
+
+func TestInMemorySearchHighlights(t *testing.T) {
+	repo := NewInMemoryPostRepository()
+	long := strings.Repeat("lorem ", 30) + "Needle here " + strings.Repeat("ipsum ", 30)
+	createPosts(t, repo,
+		testPost("Finding a Needle", "a short needle and another NEEDLE", 0),
+		testPost("haystack", long, time.Hour),
+	)
+
+	_, results := searchTitles(t, repo, models.SearchQuery{Query: "needle", Limit: 10})
+	if len(results.Data) != 2 {
+		t.Fatalf("got %d hits, want 2", len(results.Data))
+	}
+	hits := make(map[string]*models.PostSearchHit)
+	for _, hit := range results.Data {
+		hits[hit.Post.Title] = hit
+	}
+
+	short := hits["Finding a Needle"]
+	if want := "Finding a <mark>Needle</mark>"; short.TitleHighlight != want {
+		t.Errorf("title highlight = %q, want %q", short.TitleHighlight, want)
+	}
+	if want := "a short <mark>needle</mark> and another <mark>NEEDLE</mark>"; short.ContentHighlight != want {
+		t.Errorf("short content highlight = %q, want %q", short.ContentHighlight, want)
+	}
+
+	snippet := hits["haystack"].ContentHighlight
+	if hits["haystack"].TitleHighlight != "haystack" {
+		t.Errorf("title without a match = %q, want it unmarked", hits["haystack"].TitleHighlight)
+	}
+	if !strings.HasPrefix(snippet, "... lorem ") || !strings.HasSuffix(snippet, " ...") {
+		t.Errorf("snippet %q is not trimmed to whole words on both sides", snippet)
+	}
+	if !strings.Contains(snippet, "<mark>Needle</mark> here") {
+		t.Errorf("snippet %q does not highlight the match", snippet)
+	}
+	if plain := strings.NewReplacer("<mark>", "", "</mark>", "").Replace(snippet); len(plain) > 2*snippetRadius+len("... ")+len(" ...") {
+		t.Errorf("snippet is %d bytes, want at most %d around the match", len(plain), 2*snippetRadius)
+	}
+}
+
+// findFixtures creates posts for the listing tests. bravo, charlie and
+// foxtrot share a creation time, so their IDs break the tie.
+func findFixtures(t *testing.T, repo PostRepository) {
+	t.Helper()
+	post := func(n int, title, author string, created time.Duration, tags ...string) *models.Post {
+		p := testPost(title, "content", created)
+		p.ID = fmt.Sprintf("00000000-0000-4000-8000-%012d", n)
+		p.Author = author
+		p.Tags = models.NewTags(tags)
+		return p
+	}
+	draft := post(4, "delta", "bob", 3*time.Hour)
+	draft.Status = models.PostStatusDraft
+	trashed := post(5, "echo", "ann", 4*time.Hour)
+
+	createPosts(t, repo,
+		post(1, "alpha", "ann", 1*time.Hour, "go"),
+		post(2, "bravo", "bob", 2*time.Hour, "go", "k8s"),
+		post(3, "charlie", "ann", 2*time.Hour, "k8s"),
+		draft,
+		trashed,
+		post(6, "foxtrot", "bob", 2*time.Hour),
+	)
+	if err := repo.Delete(context.Background(), trashed.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func findTitles(t *testing.T, repo PostRepository, query models.PostQuery) ([]string, *models.PostPage) {
+	t.Helper()
+	if query.Page.Limit == 0 {
+		query.Page.Limit = models.DefaultPageLimit
+	}
+	page, err := repo.Find(context.Background(), query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	titles := make([]string, 0, len(page.Data))
+	for _, post := range page.Data {
+		titles = append(titles, post.Title)
+	}
+	return titles, page
+}
+
+func mustParseSort(t *testing.T, spec string) []models.SortField {
+	t.Helper()
+	sort, err := models.ParseSort(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sort
+}
+
+func TestFindFiltersAndSorts(t *testing.T) {
+	at := func(hours int) *time.Time {
+		t := time.Date(2024, 1, 1, hours, 0, 0, 0, time.UTC)
+		return &t
+	}
+
+	tests := []struct {
+		name  string
+		query models.PostQuery
+		sort  string
+		want  []string
+	}{
+		{name: "newest first by default, ties by ID", want: []string{"delta", "foxtrot", "charlie", "bravo", "alpha"}},
+		{name: "author", query: models.PostQuery{Author: "ann"}, want: []string{"charlie", "alpha"}},
+		{name: "status", query: models.PostQuery{Status: models.PostStatusPublished}, want: []string{"foxtrot", "charlie", "bravo", "alpha"}},
+		{name: "author and status", query: models.PostQuery{Author: "bob", Status: models.PostStatusPublished}, want: []string{"foxtrot", "bravo"}},
+		{name: "created after is exclusive", query: models.PostQuery{CreatedAfter: at(2)}, want: []string{"delta"}},
+		{name: "created before is exclusive", query: models.PostQuery{CreatedBefore: at(2)}, want: []string{"alpha"}},
+		{name: "updated since is inclusive", query: models.PostQuery{UpdatedSince: at(2)}, want: []string{"delta", "foxtrot", "charlie", "bravo"}},
+		{name: "any tag", query: models.PostQuery{Tags: []string{"go", "k8s"}, TagMatch: models.TagMatchAny}, want: []string{"charlie", "bravo", "alpha"}},
+		{name: "all tags", query: models.PostQuery{Tags: []string{"go", "k8s"}, TagMatch: models.TagMatchAll}, want: []string{"bravo"}},
+		{name: "trash", query: models.PostQuery{Deleted: true}, want: []string{"echo"}},
+		{name: "title", sort: "title", want: []string{"alpha", "bravo", "charlie", "delta", "foxtrot"}},
+		{name: "title descending", sort: "-title", want: []string{"foxtrot", "delta", "charlie", "bravo", "alpha"}},
+		{name: "oldest first, ties by ID", sort: "created_at", want: []string{"alpha", "bravo", "charlie", "foxtrot", "delta"}},
+		// The ID tiebreaker follows the direction of the last key
+		{name: "author then newest", sort: "author,-created_at", want: []string{"charlie", "alpha", "delta", "foxtrot", "bravo"}},
+		{name: "author descending then oldest", sort: "-author,created_at", want: []string{"bravo", "foxtrot", "delta", "alpha", "charlie"}},
+	}
+
+	forEachPostRepository(t, func(t *testing.T, repo PostRepository) {
+		findFixtures(t, repo)
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				query := tt.query
+				query.Sort = mustParseSort(t, tt.sort)
+				titles, _ := findTitles(t, repo, query)
+				if strings.Join(titles, ",") != strings.Join(tt.want, ",") {
+					t.Errorf("got %v, want %v", titles, tt.want)
+				}
+			})
+		}
+	})
+}
+
+func TestFindCursorRoundTrip(t *testing.T) {
+	sorts := []string{"", "created_at", "title", "-title", "author,-created_at", "-author,created_at", "-updated_at,title"}
+
+	forEachPostRepository(t, func(t *testing.T, repo PostRepository) {
+		findFixtures(t, repo)
+		for _, spec := range sorts {
+			t.Run("sort="+spec, func(t *testing.T) {
+				sort := mustParseSort(t, spec)
+				all, _ := findTitles(t, repo, models.PostQuery{Sort: sort})
+
+				// Walk the listing two posts at a time through the encoded cursors
+				var walked []string
+				cursor := ""
+				for pages := 0; ; pages++ {
+					if pages > len(all) {
+						t.Fatalf("cursor never ran out after %v", walked)
+					}
+					page, err := models.NewPageRequest(2, cursor)
+					if err != nil {
+						t.Fatal(err)
+					}
+					titles, result := findTitles(t, repo, models.PostQuery{Sort: sort, Page: page})
+					walked = append(walked, titles...)
+					if !result.Page.HasMore {
+						if result.Page.NextCursor != "" {
+							t.Errorf("last page has a next cursor")
+						}
+						break
+					}
+					cursor = result.Page.NextCursor
+				}
+
+				if strings.Join(walked, ",") != strings.Join(all, ",") {
+					t.Errorf("pages = %v, want %v", walked, all)
+				}
+			})
+		}
+	})
+}
+
+func TestFindRejectsInvalidCursor(t *testing.T) {
+	forEachPostRepository(t, func(t *testing.T, repo PostRepository) {
+		findFixtures(t, repo)
+		_, first := findTitles(t, repo, models.PostQuery{Page: models.PageRequest{Limit: 2}})
+		issued, err := models.DecodeCursor(first.Page.NextCursor)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tampered := func(change func(c *models.Cursor)) *models.Cursor {
+			c := *issued
+			c.Values = append([]string(nil), issued.Values...)
+			change(&c)
+			return &c
+		}
+		tests := []struct {
+			name   string
+			sort   string
+			cursor *models.Cursor
+		}{
+			{"issued for another sort", "title", issued},
+			{"sort rewritten", "", tampered(func(c *models.Cursor) { c.Sort = "title" })},
+			{"value dropped", "", tampered(func(c *models.Cursor) { c.Values = nil })},
+			{"value added", "", tampered(func(c *models.Cursor) { c.Values = append(c.Values, "x") })},
+			{"malformed time", "", tampered(func(c *models.Cursor) { c.Values[0] = "yesterday" })},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				query := models.PostQuery{
+					Sort: mustParseSort(t, tt.sort),
+					Page: models.PageRequest{Limit: 2, Cursor: tt.cursor},
+				}
+				if _, err := repo.Find(context.Background(), query); !errors.Is(err, models.ErrInvalidCursor) {
+					t.Errorf("got error %v, want ErrInvalidCursor", err)
+				}
+				if err := query.Validate(); !errors.Is(err, models.ErrInvalidCursor) {
+					t.Errorf("Validate = %v, want ErrInvalidCursor", err)
+				}
+			})
+		}
+	})
+}
+
+func TestDecodeCursorRejectsGarbage(t *testing.T) {
+	for _, raw := range []string{
+		"not base64!",
+		base64.RawURLEncoding.EncodeToString([]byte("not json")),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"s":"-created_at","v":["2024-01-01T00:00:00Z"]}`)),
+	} {
+		if _, err := models.DecodeCursor(raw); !errors.Is(err, models.ErrInvalidCursor) {
+			t.Errorf("DecodeCursor(%q) = %v, want ErrInvalidCursor", raw, err)
+		}
+		if _, err := models.NewPageRequest(10, raw); !errors.Is(err, models.ErrInvalidCursor) {
+			t.Errorf("NewPageRequest with %q = %v, want ErrInvalidCursor", raw, err)
+		}
+	}
+}
+
+func TestSlugCollisions(t *testing.T) {
+	forEachPostRepository(t, func(t *testing.T, repo PostRepository) {
+		ctx := context.Background()
+		first := testPost("Hello World", "first", 0)
+		second := testPost("Hello, world!", "second", time.Hour)
+		third := testPost("hello world", "third", 2*time.Hour)
+		createPosts(t, repo, first, second, third)
+
+		for post, want := range map[*models.Post]string{first: "hello-world", second: "hello-world-2", third: "hello-world-3"} {
+			if post.Slug != want {
+				t.Errorf("%s got slug %q, want %q", post.Content, post.Slug, want)
+			}
+			got, err := repo.GetBySlug(ctx, want)
+			if err != nil || got.ID != post.ID {
+				t.Errorf("GetBySlug(%q) = %v, %v; want the %s post", want, got, err, post.Content)
+			}
+		}
+
+		// A title with the same base keeps its numbered slug
+		updated, err := repo.Update(ctx, second.ID, models.UpdatePostRequest{Title: "Hello World?"}, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if updated.Slug != "hello-world-2" {
+			t.Errorf("slug = %q after a same-base title change, want hello-world-2", updated.Slug)
+		}
+
+		// A new title gets a new slug, and the old one keeps pointing here
+		updated, err = repo.Update(ctx, first.ID, models.UpdatePostRequest{Title: "Goodbye World"}, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if updated.Slug != "goodbye-world" {
+			t.Errorf("slug = %q after renaming, want goodbye-world", updated.Slug)
+		}
+		if got, err := repo.GetBySlug(ctx, "hello-world"); err != nil || got.ID != first.ID {
+			t.Errorf("old slug resolves to %v, %v; want the renamed post", got, err)
+		}
+
+		// Old slugs are never handed out again
+		fourth := testPost("Hello World", "fourth", 3*time.Hour)
+		createPosts(t, repo, fourth)
+		if fourth.Slug != "hello-world-4" {
+			t.Errorf("slug = %q, want hello-world-4 past the retired hello-world", fourth.Slug)
+		}
+	})
+}
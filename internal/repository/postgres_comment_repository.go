@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"postService/internal/models"
+)
+
+type PostgresCommentRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresCommentRepository(db *gorm.DB) CommentRepository {
+	return &PostgresCommentRepository{db: db}
+}
+
+func (r *PostgresCommentRepository) Create(ctx context.Context, comment *models.Comment) error {
+	return translateError(r.db.WithContext(ctx).Omit(clause.Associations).Create(comment).Error, errCommentNotFound)
+}
+
+func (r *PostgresCommentRepository) GetByID(ctx context.Context, postID, id string) (*models.Comment, error) {
+	var comment models.Comment
+	if err := r.db.WithContext(ctx).First(&comment, "post_id = ? AND id = ?", postID, id).Error; err != nil {
+		return nil, translateError(err, errCommentNotFound)
+	}
+	return &comment, nil
+}
+
+func (r *PostgresCommentRepository) ListThreads(ctx context.Context, query models.CommentQuery) (*models.CommentPage, error) {
+	cursor, err := query.CursorTime()
+	if err != nil {
+		return nil, err
+	}
+
+	db := r.db.WithContext(ctx).Where("post_id = ? AND parent_id IS NULL", query.PostID)
+	if cursor != nil {
+		db = db.Where("(created_at, id) > (?, ?)", *cursor, query.Page.Cursor.ID)
+	}
+
+	var roots []*models.Comment
+	if err := db.Order("created_at, id").Limit(query.Page.Limit + 1).Find(&roots).Error; err != nil {
+		return nil, translateError(err, errCommentNotFound)
+	}
+
+	// Only the threads that make it onto the page need their replies
+	ids := make([]string, 0, len(roots))
+	for i, root := range roots {
+		if i == query.Page.Limit {
+			break
+		}
+		ids = append(ids, root.ID)
+	}
+
+	var replies []*models.Comment
+	if len(ids) > 0 {
+		if err := r.db.WithContext(ctx).Where("root_id IN ?", ids).Find(&replies).Error; err != nil {
+			return nil, translateError(err, errCommentNotFound)
+		}
+	}
+
+	return models.NewCommentPage(roots, replies, query), nil
+}
+
+func (r *PostgresCommentRepository) Update(ctx context.Context, postID, id string, body string) (*models.Comment, error) {
+	var comment models.Comment
+	result := r.db.WithContext(ctx).Model(&comment).
+		Clauses(clause.Returning{}).
+		Where("post_id = ? AND id = ? AND deleted_at IS NULL", postID, id).
+		Updates(map[string]interface{}{
+			"body":       body,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return nil, translateError(result.Error, errCommentNotFound)
+	}
+	if result.RowsAffected == 0 {
+		return nil, errCommentNotFound
+	}
+	return &comment, nil
+}
+
+func (r *PostgresCommentRepository) Delete(ctx context.Context, postID, id string) error {
+	var tombstone models.Comment
+	tombstone.Tombstone(time.Now())
+
+	result := r.db.WithContext(ctx).Model(&models.Comment{}).
+		Where("post_id = ? AND id = ? AND deleted_at IS NULL", postID, id).
+		Updates(map[string]interface{}{
+			"author":     tombstone.Author,
+			"body":       tombstone.Body,
+			"deleted_at": tombstone.DeletedAt,
+			"updated_at": tombstone.UpdatedAt,
+		})
+	if result.Error != nil {
+		return translateError(result.Error, errCommentNotFound)
+	}
+	if result.RowsAffected == 0 {
+		return errCommentNotFound
+	}
+	return nil
+}
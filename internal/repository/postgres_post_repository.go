@@ -1,10 +1,12 @@
 package repository
 
 import (
-	"errors"
+	"context"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"postService/internal/models"
 )
 
@@ -16,41 +18,192 @@ func NewPostgresPostRepository(db *gorm.DB) PostRepository {
 	return &PostgresPostRepository{db: db}
 }
 
-func (r *PostgresPostRepository) Create(post *models.Post) error {
-	if err := r.db.Create(post).Error; err != nil {
-		return err
+func (r *PostgresPostRepository) Create(ctx context.Context, post *models.Post) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations, "Slug").Create(post).Error; err != nil {
+			return err
+		}
+		if err := assignSlug(tx, post); err != nil {
+			return err
+		}
+		if err := replaceTags(tx, post, post.TagNames()); err != nil {
+			return err
+		}
+		return tx.Create(models.NewPostRevision(post, post.Author)).Error
+	})
+	return translateError(err, errPostNotFound)
+}
+
+func (r *PostgresPostRepository) GetByID(ctx context.Context, id string) (*models.Post, error) {
+	var post models.Post
+	if err := r.db.WithContext(ctx).First(&post, "id = ?", id).Error; err != nil {
+		return nil, translateError(err, errPostNotFound)
 	}
-	return nil
+	if err := loadTags(r.db.WithContext(ctx), &post); err != nil {
+		return nil, translateError(err, errPostNotFound)
+	}
+	return &post, nil
 }
 
-func (r *PostgresPostRepository) GetByID(id string) (*models.Post, error) {
+func (r *PostgresPostRepository) GetBySlug(ctx context.Context, slug string) (*models.Post, error) {
 	var post models.Post
-	if err := r.db.First(&post, "id = ?", id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("post not found")
-		}
-		return nil, err
+	err := r.db.WithContext(ctx).Joins("JOIN post_slugs ON post_slugs.post_id = posts.id").
+		Where("post_slugs.slug = ?", slug).
+		First(&post).Error
+	if err != nil {
+		return nil, translateError(err, errPostNotFound)
+	}
+	if err := loadTags(r.db.WithContext(ctx), &post); err != nil {
+		return nil, translateError(err, errPostNotFound)
 	}
 	return &post, nil
 }
 
-func (r *PostgresPostRepository) GetAll() ([]*models.Post, error) {
-	var posts []*models.Post
-	if err := r.db.Order("created_at DESC").Find(&posts).Error; err != nil {
+func (r *PostgresPostRepository) Find(ctx context.Context, query models.PostQuery) (*models.PostPage, error) {
+	cursor, err := query.CursorValues()
+	if err != nil {
 		return nil, err
 	}
-	return posts, nil
+
+	db := r.db.WithContext(ctx).Model(&models.Post{})
+	if query.Deleted {
+		db = db.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+	if query.Author != "" {
+		db = db.Where("author = ?", query.Author)
+	}
+	if query.CreatedAfter != nil {
+		db = db.Where("created_at > ?", *query.CreatedAfter)
+	}
+	if query.CreatedBefore != nil {
+		db = db.Where("created_at < ?", *query.CreatedBefore)
+	}
+	if query.UpdatedSince != nil {
+		db = db.Where("updated_at >= ?", *query.UpdatedSince)
+	}
+	if len(query.Tags) > 0 {
+		tagged := r.db.WithContext(ctx).Table("post_tags").
+			Select("post_tags.post_id").
+			Joins("JOIN tags ON tags.id = post_tags.tag_id").
+			Where("tags.name IN ?", query.Tags)
+		if query.TagMatch == models.TagMatchAll {
+			tagged = tagged.Group("post_tags.post_id").Having("COUNT(DISTINCT tags.name) = ?", len(query.Tags))
+		}
+		db = db.Where("posts.id IN (?)", tagged)
+	}
+	if cursor != nil {
+		db = db.Where(keysetCondition(query, cursor))
+	}
+
+	for _, f := range query.OrderBy() {
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: f.Column()}, Desc: f.Desc})
+	}
+	db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: "id"}, Desc: query.IDDesc()})
+
+	var posts []*models.Post
+	if err := db.Limit(query.Page.Limit + 1).Find(&posts).Error; err != nil {
+		return nil, translateError(err, errPostNotFound)
+	}
+	if err := loadTags(r.db.WithContext(ctx), posts...); err != nil {
+		return nil, translateError(err, errPostNotFound)
+	}
+	return models.NewPostPage(posts, query), nil
 }
 
-func (r *PostgresPostRepository) Update(id string, req models.UpdatePostRequest) (*models.Post, error) {
-	var post models.Post
-	if err := r.db.First(&post, "id = ?", id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("post not found")
+// keysetCondition builds the "sorts after the cursor" predicate for an
+// arbitrary mix of ascending and descending keys, e.g. for "title,-created_at":
+// title > $1 OR (title = $1 AND created_at < $2) OR (title = $1 AND created_at = $2 AND id < $3)
+func keysetCondition(query models.PostQuery, values []interface{}) clause.Expression {
+	order := query.OrderBy()
+	columns := make([]string, 0, len(order)+1)
+	descs := make([]bool, 0, len(order)+1)
+	for _, f := range order {
+		columns = append(columns, f.Column())
+		descs = append(descs, f.Desc)
+	}
+	columns = append(columns, "id")
+	descs = append(descs, query.IDDesc())
+	values = append(values, query.Page.Cursor.ID)
+
+	var branches []string
+	var args []interface{}
+	for i := range columns {
+		var terms []string
+		for j := 0; j < i; j++ {
+			terms = append(terms, columns[j]+" = ?")
+			args = append(args, values[j])
 		}
-		return nil, err
+		op := " > ?"
+		if descs[i] {
+			op = " < ?"
+		}
+		terms = append(terms, columns[i]+op)
+		args = append(args, values[i])
+		branches = append(branches, "("+strings.Join(terms, " AND ")+")")
 	}
 
+	return clause.Expr{SQL: "(" + strings.Join(branches, " OR ") + ")", Vars: args}
+}
+
+// searchSQL ranks matches on the generated search_vector column and only
+// computes the (comparatively expensive) ts_headline snippets for the page
+const searchSQL = `
+SELECT p.*,
+	ts_headline('english', p.title, q.query, @title_options) AS title_highlight,
+	ts_headline('english', p.content, q.query, @content_options) AS content_highlight
+FROM (
+	SELECT posts.*, ts_rank(posts.search_vector, q.query) AS rank
+	FROM posts, websearch_to_tsquery('english', @query) AS q(query)
+	WHERE posts.search_vector @@ q.query AND posts.deleted_at IS NULL AND posts.status = 'published'
+	ORDER BY rank DESC, posts.created_at DESC, posts.id DESC
+	LIMIT @limit OFFSET @offset
+) AS p, websearch_to_tsquery('english', @query) AS q(query)
+ORDER BY p.rank DESC, p.created_at DESC, p.id DESC`
+
+type searchRow struct {
+	models.Post
+	Rank             float64
+	TitleHighlight   string
+	ContentHighlight string
+}
+
+func (r *PostgresPostRepository) Search(ctx context.Context, query models.SearchQuery) (*models.PostSearchResults, error) {
+	selectors := "StartSel=" + models.HighlightStart + ", StopSel=" + models.HighlightStop
+
+	var rows []searchRow
+	err := r.db.WithContext(ctx).Raw(searchSQL, map[string]interface{}{
+		"query":           query.Query,
+		"limit":           query.Limit + 1,
+		"offset":          query.Offset,
+		"title_options":   selectors + ", HighlightAll=true",
+		"content_options": selectors + ", MaxFragments=2, MaxWords=30, MinWords=10",
+	}).Scan(&rows).Error
+	if err != nil {
+		return nil, translateError(err, errPostNotFound)
+	}
+
+	hits := make([]*models.PostSearchHit, 0, len(rows))
+	posts := make([]*models.Post, 0, len(rows))
+	for i := range rows {
+		post := rows[i].Post
+		posts = append(posts, &post)
+		hits = append(hits, &models.PostSearchHit{
+			Post:             &post,
+			Rank:             rows[i].Rank,
+			TitleHighlight:   rows[i].TitleHighlight,
+			ContentHighlight: rows[i].ContentHighlight,
+		})
+	}
+	if err := loadTags(r.db.WithContext(ctx), posts...); err != nil {
+		return nil, translateError(err, errPostNotFound)
+	}
+	return models.NewPostSearchResults(hits, query), nil
+}
+
+func (r *PostgresPostRepository) Update(ctx context.Context, id string, req models.UpdatePostRequest, expectedVersion int) (*models.Post, error) {
 	// Update fields if provided
 	updateData := make(map[string]interface{})
 	if req.Title != "" {
@@ -63,26 +216,313 @@ func (r *PostgresPostRepository) Update(id string, req models.UpdatePostRequest)
 		updateData["author"] = req.Author
 	}
 	updateData["updated_at"] = time.Now()
+	updateData["version"] = gorm.Expr("version + 1")
 
-	if err := r.db.Model(&post).Updates(updateData).Error; err != nil {
-		return nil, err
+	var post models.Post
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Compare-and-swap on version in a single statement, returning the new row
+		result := tx.Model(&post).
+			Clauses(clause.Returning{}).
+			Where("id = ?", id).
+			Scopes(matchVersion(expectedVersion)).
+			Updates(updateData)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return missingOrConflict(tx, id)
+		}
+
+		if req.Title != "" && !models.SlugMatchesBase(post.Slug, models.SlugBase(post.Title)) {
+			if err := assignSlug(tx, &post); err != nil {
+				return err
+			}
+		}
+
+		// A nil tag list leaves the tags untouched, an empty one clears them
+		if req.Tags != nil {
+			if err := replaceTags(tx, &post, req.Tags); err != nil {
+				return err
+			}
+		} else if err := loadTags(tx, &post); err != nil {
+			return err
+		}
+
+		return tx.Create(models.NewPostRevision(&post, req.Editor)).Error
+	})
+	if err != nil {
+		return nil, translateError(err, errPostNotFound)
 	}
 
-	// Fetch updated record
-	if err := r.db.First(&post, "id = ?", id).Error; err != nil {
-		return nil, err
+	return &post, nil
+}
+
+func (r *PostgresPostRepository) Delete(ctx context.Context, id string, expectedVersion int) error {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Scopes(matchVersion(expectedVersion)).Delete(&models.Post{})
+	if result.Error != nil {
+		return translateError(result.Error, errPostNotFound)
+	}
+	if result.RowsAffected == 0 {
+		return translateError(missingOrConflict(r.db.WithContext(ctx), id), errPostNotFound)
 	}
+	return nil
+}
 
+func (r *PostgresPostRepository) Restore(ctx context.Context, id string) (*models.Post, error) {
+	var post models.Post
+	result := r.db.WithContext(ctx).Unscoped().Model(&post).
+		Clauses(clause.Returning{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"updated_at": time.Now(),
+			"version":    gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return nil, translateError(result.Error, errTrashedPostNotFound)
+	}
+	if result.RowsAffected == 0 {
+		return nil, errTrashedPostNotFound
+	}
+	if err := loadTags(r.db.WithContext(ctx), &post); err != nil {
+		return nil, translateError(err, errTrashedPostNotFound)
+	}
 	return &post, nil
 }
 
-func (r *PostgresPostRepository) Delete(id string) error {
-	result := r.db.Delete(&models.Post{}, "id = ?", id)
+func (r *PostgresPostRepository) Purge(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).Delete(&models.Post{})
+	if result.Error != nil {
+		return translateError(result.Error, errTrashedPostNotFound)
+	}
+	if result.RowsAffected == 0 {
+		return errTrashedPostNotFound
+	}
+	return nil
+}
+
+func (r *PostgresPostRepository) ListRevisions(ctx context.Context, postID string) ([]*models.PostRevision, error) {
+	var revisions []*models.PostRevision
+	if err := r.db.WithContext(ctx).Where("post_id = ?", postID).Order("revision DESC").Find(&revisions).Error; err != nil {
+		return nil, translateError(err, errRevisionNotFound)
+	}
+	return revisions, nil
+}
+
+func (r *PostgresPostRepository) GetRevision(ctx context.Context, postID string, revision int) (*models.PostRevision, error) {
+	var rev models.PostRevision
+	if err := r.db.WithContext(ctx).First(&rev, "post_id = ? AND revision = ?", postID, revision).Error; err != nil {
+		return nil, translateError(err, errRevisionNotFound)
+	}
+	return &rev, nil
+}
+
+func (r *PostgresPostRepository) Transition(ctx context.Context, id string, status models.PostStatus, publishAt *time.Time, expectedVersion int) (*models.Post, error) {
+	var post models.Post
+	result := r.db.WithContext(ctx).Model(&post).
+		Clauses(clause.Returning{}).
+		Where("id = ? AND status IN ?", id, models.TransitionSources(status)).
+		Scopes(matchVersion(expectedVersion)).
+		Updates(map[string]interface{}{
+			"status":     status,
+			"publish_at": publishAt,
+			"updated_at": time.Now(),
+			"version":    gorm.Expr("version + 1"),
+		})
 	if result.Error != nil {
-		return result.Error
+		return nil, translateError(result.Error, errPostNotFound)
 	}
 	if result.RowsAffected == 0 {
-		return errors.New("post not found")
+		return nil, translateError(r.transitionConflict(ctx, id, expectedVersion), errPostNotFound)
+	}
+	if err := loadTags(r.db.WithContext(ctx), &post); err != nil {
+		return nil, translateError(err, errPostNotFound)
+	}
+	return &post, nil
+}
+
+// transitionConflict explains why a transition matched no rows
+func (r *PostgresPostRepository) transitionConflict(ctx context.Context, id string, expectedVersion int) error {
+	var post models.Post
+	if err := r.db.WithContext(ctx).Select("version").First(&post, "id = ?", id).Error; err != nil {
+		return err
+	}
+	if expectedVersion != 0 && post.Version != expectedVersion {
+		return ErrVersionMismatch
+	}
+	return ErrInvalidTransition
+}
+
+func (r *PostgresPostRepository) PublishDue(ctx context.Context, now time.Time) (int64, error) {
+	// A single UPDATE is safe to run from every replica at once: a post can
+	// only leave the scheduled status once
+	result := r.db.WithContext(ctx).Model(&models.Post{}).
+		Where("status = ? AND publish_at <= ?", models.PostStatusScheduled, now).
+		Updates(map[string]interface{}{
+			"status":     models.PostStatusPublished,
+			"updated_at": now,
+			"version":    gorm.Expr("version + 1"),
+		})
+	return result.RowsAffected, translateError(result.Error, errPostNotFound)
+}
+
+func (r *PostgresPostRepository) ListTags(ctx context.Context, limit int) ([]models.TagCount, error) {
+	var counts []models.TagCount
+	err := r.db.WithContext(ctx).Table("tags").
+		Select("tags.name, COUNT(posts.id) AS count").
+		Joins("JOIN post_tags ON post_tags.tag_id = tags.id").
+		Joins("JOIN posts ON posts.id = post_tags.post_id AND posts.deleted_at IS NULL AND posts.status = ?", models.PostStatusPublished).
+		Group("tags.name").
+		Order("count DESC, tags.name").
+		Limit(limit).
+		Scan(&counts).Error
+	if err != nil {
+		return nil, translateError(err, errPostNotFound)
+	}
+	return counts, nil
+}
+
+// BackfillSlugs gives a slug to every post created before posts had slugs,
+// including posts in the trash
+func BackfillSlugs(ctx context.Context, db *gorm.DB) error {
+	db = db.WithContext(ctx)
+	var posts []*models.Post
+	if err := db.Unscoped().Where("slug IS NULL OR slug = ''").Find(&posts).Error; err != nil {
+		return err
+	}
+	for _, post := range posts {
+		if err := db.Transaction(func(tx *gorm.DB) error { return assignSlug(tx, post) }); err != nil {
+			return err
+		}
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// assignSlug derives a slug from the post title and makes it the current slug
+func assignSlug(tx *gorm.DB, post *models.Post) error {
+	slug, err := reserveSlug(tx, post.ID, models.SlugBase(post.Title))
+	if err != nil {
+		return err
+	}
+	// UpdateColumn leaves updated_at alone: a new slug is not an edit
+	if err := tx.Model(&models.Post{}).Unscoped().Where("id = ?", post.ID).UpdateColumn("slug", slug).Error; err != nil {
+		return err
+	}
+	post.Slug = slug
+	return nil
+}
+
+// reserveSlug claims the first free candidate for base in the slug history.
+// A candidate the post already owns, from an earlier title, is reused.
+func reserveSlug(tx *gorm.DB, postID, base string) (string, error) {
+	var taken []models.PostSlug
+	if err := tx.Where("slug = ? OR slug LIKE ?", base, base+"-%").Find(&taken).Error; err != nil {
+		return "", err
+	}
+	owners := make(map[string]string, len(taken))
+	for _, s := range taken {
+		owners[s.Slug] = s.PostID
+	}
+
+	for n := 1; ; n++ {
+		candidate := models.SlugCandidate(base, n)
+		if owner, ok := owners[candidate]; ok {
+			if owner == postID {
+				return candidate, nil
+			}
+			continue
+		}
+
+		// A concurrent writer may claim the same candidate; the primary key
+		// decides who gets it and the loser moves on to the next one
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.PostSlug{Slug: candidate, PostID: postID})
+		if result.Error != nil {
+			return "", result.Error
+		}
+		if result.RowsAffected == 1 {
+			return candidate, nil
+		}
+	}
+}
+
+// replaceTags makes names the complete tag set of a post, creating missing tags
+func replaceTags(tx *gorm.DB, post *models.Post, names []string) error {
+	tags := []models.Tag{}
+	if len(names) > 0 {
+		// Concurrent writers may create the same tag, so insert what is
+		// missing and read back the IDs instead of relying on RETURNING
+		err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "name"}}, DoNothing: true}).
+			Create(models.NewTags(names)).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("name IN ?", names).Order("name").Find(&tags).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Model(post).Association("Tags").Replace(tags); err != nil {
+		return err
+	}
+	post.Tags = tags
+	return nil
+}
+
+// loadTags fills in the tags of posts with a single query
+func loadTags(db *gorm.DB, posts ...*models.Post) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	byID := make(map[string]*models.Post, len(posts))
+	ids := make([]string, 0, len(posts))
+	for _, post := range posts {
+		post.Tags = []models.Tag{}
+		byID[post.ID] = post
+		ids = append(ids, post.ID)
+	}
+
+	var rows []struct {
+		PostID string
+		models.Tag
+	}
+	err := db.Table("post_tags").
+		Select("post_tags.post_id, tags.id, tags.name").
+		Joins("JOIN tags ON tags.id = post_tags.tag_id").
+		Where("post_tags.post_id IN ?", ids).
+		Order("tags.name").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		if post, ok := byID[row.PostID]; ok {
+			post.Tags = append(post.Tags, row.Tag)
+		}
+	}
+	return nil
+}
+
+// matchVersion restricts a write to rows at expectedVersion, unless it is 0
+func matchVersion(expectedVersion int) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if expectedVersion == 0 {
+			return db
+		}
+		return db.Where("version = ?", expectedVersion)
+	}
+}
+
+// missingOrConflict explains why a conditional write matched no rows
+func missingOrConflict(db *gorm.DB, id string) error {
+	var count int64
+	if err := db.Model(&models.Post{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return errPostNotFound
+	}
+	return ErrVersionMismatch
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"postService/internal/database"
+)
+
+// forEachPostRepository runs test against a fresh in-memory repository and,
+// when TEST_DATABASE_URL is set, a fresh Postgres one, so both are held to
+// the same behaviour
+func forEachPostRepository(t *testing.T, test func(t *testing.T, repo PostRepository)) {
+	t.Run("memory", func(t *testing.T) {
+		test(t, NewInMemoryPostRepository())
+	})
+	t.Run("postgres", func(t *testing.T) {
+		test(t, NewPostgresPostRepository(testGormDB(t)))
+	})
+}
+
+// testGormDB connects to the Postgres named by TEST_DATABASE_URL, in a
+// migrated schema of its own that is dropped when the test ends
+func testGormDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	admin, err := sql.Open("pgx", url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Close() })
+
+	schema := fmt.Sprintf("repository_test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Exec("DROP SCHEMA " + schema + " CASCADE") })
+
+	cfg, err := pgx.ParseConfig(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.RuntimeParams["search_path"] = schema
+	sqlDB := stdlib.OpenDB(*cfg)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	migrator, err := database.NewMigrator(sqlDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
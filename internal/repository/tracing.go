@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+
+	"postService/internal/models"
+	"postService/internal/tracing"
+)
+
+var tracer = otel.Tracer("postService/internal/repository")
+
+// tracedPostRepository runs every call of the PostRepository it wraps in a
+// span, the parent of the SQL spans the GORM plugin records
+type tracedPostRepository struct {
+	repo PostRepository
+}
+
+func TracePostRepository(repo PostRepository) PostRepository {
+	return &tracedPostRepository{repo: repo}
+}
+
+func (r *tracedPostRepository) Create(ctx context.Context, post *models.Post) error {
+	return tracing.RunErr(ctx, tracer, "PostRepository.Create", func(ctx context.Context) error {
+		return r.repo.Create(ctx, post)
+	}, tracing.PostID(post.ID))
+}
+
+func (r *tracedPostRepository) GetByID(ctx context.Context, id string) (*models.Post, error) {
+	return tracing.Run(ctx, tracer, "PostRepository.GetByID", func(ctx context.Context) (*models.Post, error) {
+		return r.repo.GetByID(ctx, id)
+	}, tracing.PostID(id))
+}
+
+func (r *tracedPostRepository) GetBySlug(ctx context.Context, slug string) (*models.Post, error) {
+	return tracing.Run(ctx, tracer, "PostRepository.GetBySlug", func(ctx context.Context) (*models.Post, error) {
+		return r.repo.GetBySlug(ctx, slug)
+	})
+}
+
+func (r *tracedPostRepository) Find(ctx context.Context, query models.PostQuery) (*models.PostPage, error) {
+	return tracing.Run(ctx, tracer, "PostRepository.Find", func(ctx context.Context) (*models.PostPage, error) {
+		return r.repo.Find(ctx, query)
+	})
+}
+
+func (r *tracedPostRepository) Search(ctx context.Context, query models.SearchQuery) (*models.PostSearchResults, error) {
+	return tracing.Run(ctx, tracer, "PostRepository.Search", func(ctx context.Context) (*models.PostSearchResults, error) {
+		return r.repo.Search(ctx, query)
+	})
+}
+
+func (r *tracedPostRepository) Update(ctx context.Context, id string, req models.UpdatePostRequest, expectedVersion int) (*models.Post, error) {
+	return tracing.Run(ctx, tracer, "PostRepository.Update", func(ctx context.Context) (*models.Post, error) {
+		return r.repo.Update(ctx, id, req, expectedVersion)
+	}, tracing.PostID(id))
+}
+
+func (r *tracedPostRepository) Delete(ctx context.Context, id string, expectedVersion int) error {
+	return tracing.RunErr(ctx, tracer, "PostRepository.Delete", func(ctx context.Context) error {
+		return r.repo.Delete(ctx, id, expectedVersion)
+	}, tracing.PostID(id))
+}
+
+func (r *tracedPostRepository) Restore(ctx context.Context, id string) (*models.Post, error) {
+	return tracing.Run(ctx, tracer, "PostRepository.Restore", func(ctx context.Context) (*models.Post, error) {
+		return r.repo.Restore(ctx, id)
+	}, tracing.PostID(id))
+}
+
+func (r *tracedPostRepository) Purge(ctx context.Context, id string) error {
+	return tracing.RunErr(ctx, tracer, "PostRepository.Purge", func(ctx context.Context) error {
+		return r.repo.Purge(ctx, id)
+	}, tracing.PostID(id))
+}
+
+func (r *tracedPostRepository) ListRevisions(ctx context.Context, postID string) ([]*models.PostRevision, error) {
+	return tracing.Run(ctx, tracer, "PostRepository.ListRevisions", func(ctx context.Context) ([]*models.PostRevision, error) {
+		return r.repo.ListRevisions(ctx, postID)
+	}, tracing.PostID(postID))
+}
+
+func (r *tracedPostRepository) GetRevision(ctx context.Context, postID string, revision int) (*models.PostRevision, error) {
+	return tracing.Run(ctx, tracer, "PostRepository.GetRevision", func(ctx context.Context) (*models.PostRevision, error) {
+		return r.repo.GetRevision(ctx, postID, revision)
+	}, tracing.PostID(postID))
+}
+
+func (r *tracedPostRepository) Transition(ctx context.Context, id string, status models.PostStatus, publishAt *time.Time, expectedVersion int) (*models.Post, error) {
+	return tracing.Run(ctx, tracer, "PostRepository.Transition", func(ctx context.Context) (*models.Post, error) {
+		return r.repo.Transition(ctx, id, status, publishAt, expectedVersion)
+	}, tracing.PostID(id))
+}
+
+func (r *tracedPostRepository) PublishDue(ctx context.Context, now time.Time) (int64, error) {
+	return tracing.Run(ctx, tracer, "PostRepository.PublishDue", func(ctx context.Context) (int64, error) {
+		return r.repo.PublishDue(ctx, now)
+	})
+}
+
+func (r *tracedPostRepository) ListTags(ctx context.Context, limit int) ([]models.TagCount, error) {
+	return tracing.Run(ctx, tracer, "PostRepository.ListTags", func(ctx context.Context) ([]models.TagCount, error) {
+		return r.repo.ListTags(ctx, limit)
+	})
+}
+
+// tracedCommentRepository runs every call of the CommentRepository it wraps
+// in a span
+type tracedCommentRepository struct {
+	repo CommentRepository
+}
+
+func TraceCommentRepository(repo CommentRepository) CommentRepository {
+	return &tracedCommentRepository{repo: repo}
+}
+
+func (r *tracedCommentRepository) Create(ctx context.Context, comment *models.Comment) error {
+	return tracing.RunErr(ctx, tracer, "CommentRepository.Create", func(ctx context.Context) error {
+		return r.repo.Create(ctx, comment)
+	}, tracing.PostID(comment.PostID), tracing.CommentID(comment.ID))
+}
+
+func (r *tracedCommentRepository) GetByID(ctx context.Context, postID, id string) (*models.Comment, error) {
+	return tracing.Run(ctx, tracer, "CommentRepository.GetByID", func(ctx context.Context) (*models.Comment, error) {
+		return r.repo.GetByID(ctx, postID, id)
+	}, tracing.PostID(postID), tracing.CommentID(id))
+}
+
+func (r *tracedCommentRepository) ListThreads(ctx context.Context, query models.CommentQuery) (*models.CommentPage, error) {
+	return tracing.Run(ctx, tracer, "CommentRepository.ListThreads", func(ctx context.Context) (*models.CommentPage, error) {
+		return r.repo.ListThreads(ctx, query)
+	}, tracing.PostID(query.PostID))
+}
+
+func (r *tracedCommentRepository) Update(ctx context.Context, postID, id string, body string) (*models.Comment, error) {
+	return tracing.Run(ctx, tracer, "CommentRepository.Update", func(ctx context.Context) (*models.Comment, error) {
+		return r.repo.Update(ctx, postID, id, body)
+	}, tracing.PostID(postID), tracing.CommentID(id))
+}
+
+func (r *tracedCommentRepository) Delete(ctx context.Context, postID, id string) error {
+	return tracing.RunErr(ctx, tracer, "CommentRepository.Delete", func(ctx context.Context) error {
+		return r.repo.Delete(ctx, postID, id)
+	}, tracing.PostID(postID), tracing.CommentID(id))
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"postService/internal/models"
+	"postService/internal/repository"
+)
+
+type CommentService interface {
+	CreateComment(ctx context.Context, postID string, req models.CreateCommentRequest) (*models.Comment, error)
+	GetComment(ctx context.Context, postID, id string) (*models.Comment, error)
+	ListComments(ctx context.Context, query models.CommentQuery) (*models.CommentPage, error)
+	UpdateComment(ctx context.Context, postID, id string, req models.UpdateCommentRequest) (*models.Comment, error)
+	DeleteComment(ctx context.Context, postID, id string) error
+}
+
+// commentService scopes every comment operation to a post that is not in the
+// trash, so comments disappear and come back together with their post
+type commentService struct {
+	comments repository.CommentRepository
+	posts    repository.PostRepository
+}
+
+func NewCommentService(comments repository.CommentRepository, posts repository.PostRepository) CommentService {
+	return &commentService{comments: comments, posts: posts}
+}
+
+// CreateComment adds a comment to a published post, as a reply when the
+// request names a parent comment
+func (s *commentService) CreateComment(ctx context.Context, postID string, req models.CreateCommentRequest) (*models.Comment, error) {
+	post, err := s.posts.GetByID(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	if post.Status != models.PostStatusPublished {
+		return nil, ErrCommentsClosed
+	}
+
+	var parent *models.Comment
+	if req.ParentID != "" {
+		parent, err = s.comments.GetByID(ctx, postID, req.ParentID)
+		if errors.Is(err, ErrNotFound) {
+			return nil, NewValidationError("parent_id", "does not match a comment on this post")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if parent.DeletedAt != nil {
+			return nil, NewValidationError("parent_id", "refers to a deleted comment")
+		}
+	}
+
+	comment, err := models.NewComment(postID, req, parent)
+	if err != nil {
+		return nil, NewValidationError("parent_id", err.Error())
+	}
+	if err := s.comments.Create(ctx, comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+func (s *commentService) GetComment(ctx context.Context, postID, id string) (*models.Comment, error) {
+	if _, err := s.posts.GetByID(ctx, postID); err != nil {
+		return nil, err
+	}
+	return s.comments.GetByID(ctx, postID, id)
+}
+
+func (s *commentService) ListComments(ctx context.Context, query models.CommentQuery) (*models.CommentPage, error) {
+	if _, err := s.posts.GetByID(ctx, query.PostID); err != nil {
+		return nil, err
+	}
+	return s.comments.ListThreads(ctx, query)
+}
+
+func (s *commentService) UpdateComment(ctx context.Context, postID, id string, req models.UpdateCommentRequest) (*models.Comment, error) {
+	if _, err := s.posts.GetByID(ctx, postID); err != nil {
+		return nil, err
+	}
+	return s.comments.Update(ctx, postID, id, req.Body)
+}
+
+func (s *commentService) DeleteComment(ctx context.Context, postID, id string) error {
+	if _, err := s.posts.GetByID(ctx, postID); err != nil {
+		return err
+	}
+	return s.comments.Delete(ctx, postID, id)
+}
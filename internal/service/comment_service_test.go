@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"postService/internal/models"
+	"postService/internal/repository"
+)
+
+// newTestCommentService returns comment and post services sharing an
+// in-memory post repository
+func newTestCommentService() (CommentService, PostService) {
+	posts := repository.NewInMemoryPostRepository()
+	return NewCommentService(repository.NewInMemoryCommentRepository(), posts), NewPostService(posts)
+}
+
+func createTestComment(t *testing.T, comments CommentService, postID, body, parentID string) *models.Comment {
+	t.Helper()
+	comment, err := comments.CreateComment(context.Background(), postID, models.CreateCommentRequest{
+		Author:   "bob",
+		Body:     body,
+		ParentID: parentID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return comment
+}
+
+// assertFieldError fails unless err rejects the given field with message
+func assertFieldError(t *testing.T, err error, field, message string) {
+	t.Helper()
+	var verr *ValidationError
+	if !errors.As(err, &verr) || !errors.Is(err, ErrValidation) ||
+		len(verr.Fields) != 1 || verr.Fields[0].Field != field || verr.Fields[0].Message != message {
+		t.Fatalf("got error %v, want %s %s", err, field, message)
+	}
+}
+
+func listComments(t *testing.T, comments CommentService, postID string, limit int, cursor string) *models.CommentPage {
+	t.Helper()
+	query := models.CommentQuery{PostID: postID, Page: models.PageRequest{Limit: limit}}
+	if cursor != "" {
+		c, err := models.DecodeCursor(cursor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		query.Page.Cursor = c
+	}
+	page, err := comments.ListComments(context.Background(), query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return page
+}
+
+func TestCreateCommentNeedsALivePublishedPost(t *testing.T) {
+	ctx := context.Background()
+	comments, posts := newTestCommentService()
+	post := createTestPost(t, posts, "commented")
+	createTestComment(t, comments, post.ID, "before the trash", "")
+
+	_, err := comments.CreateComment(ctx, "00000000-0000-4000-8000-000000000000", models.CreateCommentRequest{Author: "bob", Body: "hi"})
+	assertKind(t, err, ErrNotFound, "post not found")
+
+	if err := posts.DeletePost(ctx, post.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	_, err = comments.CreateComment(ctx, post.ID, models.CreateCommentRequest{Author: "bob", Body: "hi"})
+	assertKind(t, err, ErrNotFound, "post not found")
+	_, err = comments.ListComments(ctx, models.CommentQuery{PostID: post.ID, Page: models.PageRequest{Limit: 10}})
+	assertKind(t, err, ErrNotFound, "post not found")
+
+	// Comments come back with their post
+	if _, err := posts.RestorePost(ctx, post.ID); err != nil {
+		t.Fatal(err)
+	}
+	if page := listComments(t, comments, post.ID, 10, ""); len(page.Data) != 1 || page.Data[0].Body != "before the trash" {
+		t.Errorf("comments after restore = %v, want the one from before", page.Data)
+	}
+
+	draft, err := posts.UnpublishPost(ctx, post.ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = comments.CreateComment(ctx, draft.ID, models.CreateCommentRequest{Author: "bob", Body: "hi"})
+	assertKind(t, err, ErrConflict, "comments are only open on published posts")
+}
+
+func TestCreateReplyThreads(t *testing.T) {
+	ctx := context.Background()
+	comments, posts := newTestCommentService()
+	post := createTestPost(t, posts, "threaded")
+	other := createTestPost(t, posts, "elsewhere")
+
+	root := createTestComment(t, comments, post.ID, "root", "")
+	reply := createTestComment(t, comments, post.ID, "reply", root.ID)
+	nested := createTestComment(t, comments, post.ID, "nested", reply.ID)
+
+	if root.ParentID != nil || root.RootID != nil || root.Depth != 0 {
+		t.Errorf("root = %+v, want a top-level comment", root)
+	}
+	if *reply.ParentID != root.ID || *reply.RootID != root.ID || reply.Depth != 1 {
+		t.Errorf("reply = %+v, want depth 1 under the root", reply)
+	}
+	if *nested.ParentID != reply.ID || *nested.RootID != root.ID || nested.Depth != 2 {
+		t.Errorf("nested = %+v, want depth 2 in the root's thread", nested)
+	}
+
+	elsewhere := createTestComment(t, comments, other.ID, "on another post", "")
+	_, err := comments.CreateComment(ctx, post.ID, models.CreateCommentRequest{Author: "bob", Body: "hi", ParentID: elsewhere.ID})
+	assertFieldError(t, err, "parent_id", "does not match a comment on this post")
+	_, err = comments.CreateComment(ctx, post.ID, models.CreateCommentRequest{Author: "bob", Body: "hi", ParentID: "missing"})
+	assertFieldError(t, err, "parent_id", "does not match a comment on this post")
+
+	if err := comments.DeleteComment(ctx, post.ID, reply.ID); err != nil {
+		t.Fatal(err)
+	}
+	_, err = comments.CreateComment(ctx, post.ID, models.CreateCommentRequest{Author: "bob", Body: "hi", ParentID: reply.ID})
+	assertFieldError(t, err, "parent_id", "refers to a deleted comment")
+
+	// The tombstone keeps its place in the thread
+	page := listComments(t, comments, post.ID, 10, "")
+	if len(page.Data) != 1 || len(page.Data[0].Replies) != 1 {
+		t.Fatalf("threads = %+v, want the root with one reply", page.Data)
+	}
+	tombstone := page.Data[0].Replies[0]
+	if tombstone.DeletedAt == nil || tombstone.Body != "" || len(tombstone.Replies) != 1 || tombstone.Replies[0].ID != nested.ID {
+		t.Errorf("tombstone = %+v, want it emptied and still holding the nested reply", tombstone)
+	}
+
+	parent := nested
+	for parent.Depth < models.MaxCommentDepth {
+		parent = createTestComment(t, comments, post.ID, fmt.Sprint("depth ", parent.Depth+1), parent.ID)
+	}
+	_, err = comments.CreateComment(ctx, post.ID, models.CreateCommentRequest{Author: "bob", Body: "too deep", ParentID: parent.ID})
+	assertFieldError(t, err, "parent_id", fmt.Sprintf("cannot nest replies more than %d levels deep", models.MaxCommentDepth))
+}
+
+func TestListCommentsPagination(t *testing.T) {
+	comments, posts := newTestCommentService()
+	post := createTestPost(t, posts, "popular")
+	other := createTestPost(t, posts, "quiet")
+	createTestComment(t, comments, other.ID, "not on this post", "")
+
+	var roots []*models.Comment
+	replies := make(map[string]string)
+	for i := 0; i < 5; i++ {
+		root := createTestComment(t, comments, post.ID, fmt.Sprint("root ", i), "")
+		roots = append(roots, root)
+		replies[root.ID] = createTestComment(t, comments, post.ID, fmt.Sprint("reply to ", i), root.ID).ID
+	}
+	sort.Slice(roots, func(i, j int) bool { return models.LessComment(roots[i], roots[j]) })
+
+	var got []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages == len(roots) {
+			t.Fatal("pagination does not end")
+		}
+		page := listComments(t, comments, post.ID, 2, cursor)
+		for _, root := range page.Data {
+			got = append(got, root.ID)
+			// Replies come nested in their thread, not as top-level comments
+			if len(root.Replies) != 1 || root.Replies[0].ID != replies[root.ID] {
+				t.Errorf("%s has replies %v, want only its own", root.Body, root.Replies)
+			}
+		}
+		if page.Page.Count != len(page.Data) || page.Page.HasMore != (page.Page.NextCursor != "") {
+			t.Errorf("page info %+v does not match %d comments", page.Page, len(page.Data))
+		}
+		if !page.Page.HasMore {
+			break
+		}
+		cursor = page.Page.NextCursor
+	}
+
+	var want []string
+	for _, root := range roots {
+		want = append(want, root.ID)
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("paged through %v, want %v oldest first", got, want)
+	}
+
+	// A cursor from another listing does not apply to comments
+	_, err := comments.ListComments(context.Background(), models.CommentQuery{
+		PostID: post.ID,
+		Page:   models.PageRequest{Limit: 2, Cursor: &models.Cursor{Sort: "title", Values: []string{"a"}}},
+	})
+	if !errors.Is(err, models.ErrInvalidCursor) {
+		t.Errorf("got error %v, want ErrInvalidCursor", err)
+	}
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"strings"
+
+	"postService/internal/models"
+	"postService/internal/repository"
+)
+
+// The error kinds shared with the repository layer. Every error returned by
+// a service either is or wraps one of them, except for unexpected failures.
+var (
+	ErrNotFound    = repository.ErrNotFound
+	ErrConflict    = repository.ErrConflict
+	ErrValidation  = repository.ErrValidation
+	ErrUnavailable = repository.ErrUnavailable
+)
+
+// DomainError is an error of one of the kinds above with a message of its own
+type DomainError = repository.DomainError
+
+var (
+	// ErrVersionMismatch is returned by conditional updates and deletes when the
+	// post has been modified since the caller read it
+	ErrVersionMismatch = repository.ErrVersionMismatch
+
+	// ErrInvalidTransition is returned when a lifecycle change is not allowed
+	// from the current status of the post
+	ErrInvalidTransition = repository.ErrInvalidTransition
+
+	// ErrCommentsClosed is returned when commenting on a post that is not published
+	ErrCommentsClosed = &DomainError{Kind: ErrConflict, Message: "comments are only open on published posts"}
+
+	// ErrUnknownComponent is returned for health components nothing registered
+	ErrUnknownComponent = &DomainError{Kind: ErrNotFound, Message: "no such health component"}
+)
+
+// ValidationError is an ErrValidation that lists the rejected fields
+type ValidationError struct {
+	Fields []models.FieldError
+}
+
+// NewValidationError rejects a single field
+func NewValidationError(field, message string) *ValidationError {
+	return &ValidationError{Fields: []models.FieldError{{Field: field, Message: message}}}
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		parts = append(parts, f.Field+" "+f.Message)
+	}
+	return strings.Join(parts, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"runtime"
+	"time"
+
+	"postService/internal/cgroup"
+	"postService/internal/config"
+	"postService/internal/database"
+	"postService/internal/models"
+)
+
+// checkedDatabase is what databaseChecker needs of *database.Database
+type checkedDatabase interface {
+	Health(ctx context.Context) error
+	ServerInfo(ctx context.Context) (database.ServerInfo, error)
+	Probe(ctx context.Context, readOnly bool) error
+	Stats() (sql.DBStats, error)
+}
+
+// databaseChecker pings the database and watches connection pool usage.
+// It is critical: the service cannot serve requests without its database.
+// Slowness, a failover to a replica and a busy pool only degrade it, which
+// shows on /health but leaves readiness alone, since every replica would
+// see the same database and be pulled from the load balancer at once.
+type databaseChecker struct {
+	db         checkedDatabase
+	thresholds config.HealthConfig
+}
+
+func NewDatabaseChecker(db *database.Database, thresholds config.HealthConfig) Checker {
+	c := &databaseChecker{thresholds: thresholds}
+	if db != nil {
+		c.db = db
+	}
+	return c
+}
+
+func (c *databaseChecker) Name() string           { return "database" }
+func (c *databaseChecker) Critical() bool         { return true }
+func (c *databaseChecker) Timeout() time.Duration { return 5 * time.Second }
+
+func (c *databaseChecker) Check(ctx context.Context) models.ComponentHealth {
+	start := time.Now()
+	component := models.ComponentHealth{
+		Details: make(map[string]interface{}),
+	}
+
+	if c.db == nil {
+		component.Status = models.HealthStatusUnhealthy
+		component.Error = "Database connection not initialized"
+		return component
+	}
+
+	// Check database health
+	if err := c.db.Health(ctx); err != nil {
+		component.Status = models.HealthStatusUnhealthy
+		component.Error = fmt.Sprintf("Database health check failed: %v", err)
+		return component
+	}
+
+	info, err := c.db.ServerInfo(ctx)
+	if err != nil {
+		component.Status = models.HealthStatusUnhealthy
+		component.Error = fmt.Sprintf("Could not query server status: %v", err)
+		return component
+	}
+	component.Details["server_version"] = info.Version
+	component.Details["replication_role"] = info.ReplicationRole()
+
+	// A replica cannot take the write, and is reported as such below
+	if c.thresholds.DatabaseProbe {
+		if err := c.db.Probe(ctx, info.InRecovery); err != nil {
+			component.Status = models.HealthStatusUnhealthy
+			component.Error = fmt.Sprintf("Database probe failed: %v", err)
+			return component
+		}
+	}
+	latency := time.Since(start)
+	component.Details["latency_ms"] = latency.Milliseconds()
+
+	// Get database statistics
+	stats, err := c.db.Stats()
+	if err != nil {
+		component.Status = models.HealthStatusDegraded
+		component.Message = "Could not get connection stats"
+		component.Error = err.Error()
+		return component
+	}
+
+	component.Details["open_connections"] = stats.OpenConnections
+	component.Details["in_use"] = stats.InUse
+	component.Details["idle"] = stats.Idle
+	component.Details["max_open"] = stats.MaxOpenConnections
+
+	switch {
+	case info.InRecovery:
+		component.Status = models.HealthStatusDegraded
+		component.Message = "Database is a read-only replica in recovery"
+	case latency > c.thresholds.DatabaseLatencySLO:
+		component.Status = models.HealthStatusDegraded
+		component.Message = fmt.Sprintf("Database latency above the %s SLO", c.thresholds.DatabaseLatencySLO)
+	// Check if we're approaching connection limits
+	case stats.MaxOpenConnections > 0 && percent(uint64(stats.InUse), uint64(stats.MaxOpenConnections)) >= c.thresholds.DatabasePoolDegradedPercent:
+		component.Status = models.HealthStatusDegraded
+		component.Message = "High connection usage detected"
+	default:
+		component.Status = models.HealthStatusHealthy
+		component.Message = "Database connection healthy"
+	}
+	return component
+}
+
+// memoryChecker compares memory usage against thresholds. In a container
+// with a memory limit that is the working set relative to the limit, since
+// the limit is what gets the process killed; elsewhere it is the heap size.
+type memoryChecker struct {
+	thresholds config.HealthConfig
+	cgroupRoot string
+}
+
+func NewMemoryChecker(thresholds config.HealthConfig) Checker {
+	return &memoryChecker{thresholds: thresholds, cgroupRoot: cgroup.DefaultRoot}
+}
+
+func (c *memoryChecker) Name() string           { return "memory" }
+func (c *memoryChecker) Critical() bool         { return false }
+func (c *memoryChecker) Timeout() time.Duration { return time.Second }
+
+func (c *memoryChecker) Check(ctx context.Context) models.ComponentHealth {
+	component := models.ComponentHealth{
+		Details: make(map[string]interface{}),
+	}
+
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	// Convert bytes to MB for readability
+	allocMB := m.Alloc / 1024 / 1024
+	sysMB := m.Sys / 1024 / 1024
+
+	component.Details["alloc_mb"] = allocMB
+	component.Details["sys_mb"] = sysMB
+	component.Details["num_gc"] = m.NumGC
+
+	mem, err := cgroup.ReadMemory(c.cgroupRoot)
+	if err == nil && mem.Limit > 0 {
+		used := percent(mem.WorkingSet, mem.Limit)
+		component.Details["limit_mb"] = mem.Limit / 1024 / 1024
+		component.Details["working_set_mb"] = mem.WorkingSet / 1024 / 1024
+		component.Details["limit_used_percent"] = math.Round(used*10) / 10
+
+		switch {
+		case used >= c.thresholds.MemoryUnhealthyPercent:
+			component.Status = models.HealthStatusUnhealthy
+			component.Message = "Memory usage close to the container limit"
+		case used >= c.thresholds.MemoryDegradedPercent:
+			component.Status = models.HealthStatusDegraded
+			component.Message = "High memory usage relative to the container limit"
+		default:
+			component.Status = models.HealthStatusHealthy
+			component.Message = "Memory usage normal"
+		}
+		return component
+	}
+
+	// Without a limit, judge the heap, checking the most severe level first
+	switch {
+	case allocMB > c.thresholds.MemoryUnhealthyMB:
+		component.Status = models.HealthStatusUnhealthy
+		component.Message = "Critical memory usage"
+	case allocMB > c.thresholds.MemoryDegradedMB:
+		component.Status = models.HealthStatusDegraded
+		component.Message = "High memory usage detected"
+	default:
+		component.Status = models.HealthStatusHealthy
+		component.Message = "Memory usage normal"
+	}
+	return component
+}
+
+// goroutineChecker compares the number of goroutines against thresholds,
+// which catches leaks long before they exhaust memory
+type goroutineChecker struct {
+	thresholds config.HealthConfig
+}
+
+func NewGoroutineChecker(thresholds config.HealthConfig) Checker {
+	return &goroutineChecker{thresholds: thresholds}
+}
+
+func (c *goroutineChecker) Name() string           { return "goroutines" }
+func (c *goroutineChecker) Critical() bool         { return false }
+func (c *goroutineChecker) Timeout() time.Duration { return time.Second }
+
+func (c *goroutineChecker) Check(ctx context.Context) models.ComponentHealth {
+	component := models.ComponentHealth{
+		Details: make(map[string]interface{}),
+	}
+
+	numGoroutines := runtime.NumGoroutine()
+	component.Details["count"] = numGoroutines
+
+	// Assess goroutine count, checking the most severe level first
+	switch {
+	case numGoroutines > c.thresholds.GoroutinesUnhealthy:
+		component.Status = models.HealthStatusUnhealthy
+		component.Message = "Critical goroutine count"
+	case numGoroutines > c.thresholds.GoroutinesDegraded:
+		component.Status = models.HealthStatusDegraded
+		component.Message = "High goroutine count detected"
+	default:
+		component.Status = models.HealthStatusHealthy
+		component.Message = "Goroutine count normal"
+	}
+	return component
+}
+
+func percent(part, whole uint64) float64 {
+	return float64(part) * 100 / float64(whole)
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"postService/internal/config"
+	"postService/internal/database"
+	"postService/internal/models"
+)
+
+// fakeDatabase answers the database checker with canned results, taking
+// delay over the ping to simulate a slow server
+type fakeDatabase struct {
+	delay    time.Duration
+	pingErr  error
+	info     database.ServerInfo
+	infoErr  error
+	probeErr error
+	probedRO *bool
+	stats    sql.DBStats
+	statsErr error
+}
+
+func (f *fakeDatabase) Health(ctx context.Context) error {
+	time.Sleep(f.delay)
+	return f.pingErr
+}
+
+func (f *fakeDatabase) ServerInfo(ctx context.Context) (database.ServerInfo, error) {
+	return f.info, f.infoErr
+}
+
+func (f *fakeDatabase) Probe(ctx context.Context, readOnly bool) error {
+	f.probedRO = &readOnly
+	return f.probeErr
+}
+
+func (f *fakeDatabase) Stats() (sql.DBStats, error) {
+	return f.stats, f.statsErr
+}
+
+func TestDatabaseCheckerCheck(t *testing.T) {
+	thresholds := config.Default().Health
+	thresholds.DatabaseProbe = true
+	thresholds.DatabaseLatencySLO = 20 * time.Millisecond
+	failure := errors.New("boom")
+
+	tests := []struct {
+		name    string
+		db      *fakeDatabase
+		status  models.HealthStatus
+		message string
+		error   string
+	}{
+		{"healthy", &fakeDatabase{info: database.ServerInfo{Version: "16.2"}},
+			models.HealthStatusHealthy, "Database connection healthy", ""},
+		{"ping fails", &fakeDatabase{pingErr: failure},
+			models.HealthStatusUnhealthy, "", "Database health check failed: boom"},
+		{"server info fails", &fakeDatabase{infoErr: failure},
+			models.HealthStatusUnhealthy, "", "Could not query server status: boom"},
+		{"probe fails", &fakeDatabase{probeErr: failure},
+			models.HealthStatusUnhealthy, "", "Database probe failed: boom"},
+		{"in recovery", &fakeDatabase{info: database.ServerInfo{InRecovery: true}},
+			models.HealthStatusDegraded, "Database is a read-only replica in recovery", ""},
+		{"above latency SLO", &fakeDatabase{delay: 30 * time.Millisecond},
+			models.HealthStatusDegraded, "Database latency above the 20ms SLO", ""},
+		{"pool busy", &fakeDatabase{stats: sql.DBStats{MaxOpenConnections: 10, InUse: 8}},
+			models.HealthStatusDegraded, "High connection usage detected", ""},
+		{"stats fail", &fakeDatabase{statsErr: failure},
+			models.HealthStatusDegraded, "Could not get connection stats", "boom"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := &databaseChecker{db: tt.db, thresholds: thresholds}
+			got := checker.Check(context.Background())
+
+			if got.Status != tt.status || got.Message != tt.message || got.Error != tt.error {
+				t.Errorf("got %s %q (error %q), want %s %q (error %q)",
+					got.Status, got.Message, got.Error, tt.status, tt.message, tt.error)
+			}
+		})
+	}
+}
+
+func TestDatabaseCheckerProbesReplicasReadOnly(t *testing.T) {
+	thresholds := config.Default().Health
+	thresholds.DatabaseProbe = true
+
+	for _, inRecovery := range []bool{false, true} {
+		db := &fakeDatabase{info: database.ServerInfo{InRecovery: inRecovery}}
+		got := (&databaseChecker{db: db, thresholds: thresholds}).Check(context.Background())
+
+		if db.probedRO == nil || *db.probedRO != inRecovery {
+			t.Errorf("in recovery %v: probe read-only = %v, want %v", inRecovery, db.probedRO, inRecovery)
+		}
+		want := "primary"
+		if inRecovery {
+			want = "replica"
+		}
+		if role := got.Details["replication_role"]; role != want {
+			t.Errorf("in recovery %v: replication_role = %v, want %s", inRecovery, role, want)
+		}
+	}
+
+	thresholds.DatabaseProbe = false
+	db := &fakeDatabase{}
+	(&databaseChecker{db: db, thresholds: thresholds}).Check(context.Background())
+	if db.probedRO != nil {
+		t.Error("probe ran although it is disabled")
+	}
+}
+
+func TestDatabaseCheckerWithoutDatabase(t *testing.T) {
+	got := NewDatabaseChecker(nil, config.Default().Health).Check(context.Background())
+	if got.Status != models.HealthStatusUnhealthy || !strings.Contains(got.Error, "not initialized") {
+		t.Errorf("got %s %q, want unhealthy for a missing database", got.Status, got.Error)
+	}
+}
+
+// fakeCgroup writes files, named relative to a new cgroup root, and returns
+// the root
+func fakeCgroup(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// cgroupV2 and cgroupV1 lay out a memory controller with a limit, usage and
+// inactive file cache, all in MB
+func cgroupV2(limit string, current, inactive uint64) map[string]string {
+	return map[string]string{
+		"memory.max":     limit,
+		"memory.current": fmt.Sprint(current << 20),
+		"memory.stat":    fmt.Sprintf("anon 1\ninactive_file %d\nactive_file 1", inactive<<20),
+	}
+}
+
+func cgroupV1(limit string, usage, inactive uint64) map[string]string {
+	return map[string]string{
+		"memory/memory.limit_in_bytes": limit,
+		"memory/memory.usage_in_bytes": fmt.Sprint(usage << 20),
+		"memory/memory.stat":           fmt.Sprintf("cache 1\ntotal_inactive_file %d", inactive<<20),
+	}
+}
+
+func TestMemoryCheckerAgainstCgroupLimit(t *testing.T) {
+	limit := fmt.Sprint(1000 << 20)
+	tests := []struct {
+		name    string
+		files   map[string]string
+		status  models.HealthStatus
+		percent float64
+	}{
+		{"v2 normal", cgroupV2(limit, 500, 100), models.HealthStatusHealthy, 40},
+		{"v2 file cache is not counted", cgroupV2(limit, 990, 500), models.HealthStatusHealthy, 49},
+		{"v2 degraded", cgroupV2(limit, 900, 50), models.HealthStatusDegraded, 85},
+		// Above both thresholds the more severe status wins
+		{"v2 unhealthy", cgroupV2(limit, 990, 0), models.HealthStatusUnhealthy, 99},
+		{"v1 normal", cgroupV1(limit, 100, 0), models.HealthStatusHealthy, 10},
+		{"v1 degraded", cgroupV1(limit, 850, 0), models.HealthStatusDegraded, 85},
+		{"v1 unhealthy", cgroupV1(limit, 960, 0), models.HealthStatusUnhealthy, 96},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := &memoryChecker{thresholds: config.Default().Health, cgroupRoot: fakeCgroup(t, tt.files)}
+			got := checker.Check(context.Background())
+
+			if got.Status != tt.status {
+				t.Errorf("status = %s (%s), want %s", got.Status, got.Message, tt.status)
+			}
+			if got.Details["limit_mb"] != uint64(1000) || got.Details["limit_used_percent"] != tt.percent {
+				t.Errorf("details = %v, want a 1000MB limit %v%% used", got.Details, tt.percent)
+			}
+		})
+	}
+}
+
+func TestMemoryCheckerFallsBackToHeap(t *testing.T) {
+	// Keep at least 8MB on the heap while checking
+	ballast := make([]byte, 8<<20)
+	defer runtime.KeepAlive(ballast)
+
+	roots := map[string]map[string]string{
+		"no cgroup":    {},
+		"v2 unlimited": cgroupV2("max", 900, 0),
+		"v1 unlimited": cgroupV1("9223372036854771712", 900, 0),
+	}
+	tests := []struct {
+		name                string
+		degraded, unhealthy uint64
+		status              models.HealthStatus
+	}{
+		{"normal", 1 << 20, 1 << 21, models.HealthStatusHealthy},
+		{"degraded", 1, 1 << 20, models.HealthStatusDegraded},
+		// Above both thresholds the more severe status wins
+		{"unhealthy", 1, 2, models.HealthStatusUnhealthy},
+	}
+	for rootName, files := range roots {
+		for _, tt := range tests {
+			t.Run(rootName+"/"+tt.name, func(t *testing.T) {
+				thresholds := config.Default().Health
+				thresholds.MemoryDegradedMB = tt.degraded
+				thresholds.MemoryUnhealthyMB = tt.unhealthy
+				checker := &memoryChecker{thresholds: thresholds, cgroupRoot: fakeCgroup(t, files)}
+				got := checker.Check(context.Background())
+
+				if got.Status != tt.status {
+					t.Errorf("status = %s (%s), want %s", got.Status, got.Message, tt.status)
+				}
+				if _, ok := got.Details["limit_mb"]; ok {
+					t.Errorf("details = %v, want no limit", got.Details)
+				}
+			})
+		}
+	}
+}
+
+func TestGoroutineCheckerSeverity(t *testing.T) {
+	tests := []struct {
+		name                string
+		degraded, unhealthy int
+		status              models.HealthStatus
+	}{
+		{"normal", 1 << 20, 1 << 21, models.HealthStatusHealthy},
+		{"degraded", 0, 1 << 20, models.HealthStatusDegraded},
+		// Above both thresholds the more severe status wins
+		{"unhealthy", 0, 0, models.HealthStatusUnhealthy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			thresholds := config.Default().Health
+			thresholds.GoroutinesDegraded = tt.degraded
+			thresholds.GoroutinesUnhealthy = tt.unhealthy
+			got := NewGoroutineChecker(thresholds).Check(context.Background())
+
+			if got.Status != tt.status {
+				t.Errorf("status = %s (%s), want %s", got.Status, got.Message, tt.status)
+			}
+		})
+	}
+}
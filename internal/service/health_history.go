@@ -0,0 +1,49 @@
+package service
+
+import (
+	"postService/internal/models"
+)
+
+// ring keeps the last len(items) values pushed into it
+type ring[T any] struct {
+	items []T
+	next  int
+	full  bool
+}
+
+func newRing[T any](size int) *ring[T] {
+	return &ring[T]{items: make([]T, size)}
+}
+
+func (r *ring[T]) push(v T) {
+	r.items[r.next] = v
+	r.next = (r.next + 1) % len(r.items)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// values returns the kept values, oldest first
+func (r *ring[T]) values() []T {
+	if !r.full {
+		return append([]T(nil), r.items[:r.next]...)
+	}
+	return append(append([]T(nil), r.items[r.next:]...), r.items[:r.next]...)
+}
+
+// componentState is what the background probe knows about a component
+type componentState struct {
+	latest  models.ComponentHealth
+	history *ring[models.HealthSample]
+}
+
+// transitions counts the status changes between consecutive samples
+func transitions(samples []models.HealthSample) int {
+	count := 0
+	for i := 1; i < len(samples); i++ {
+		if samples[i].Status != samples[i-1].Status {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"postService/internal/config"
+	"postService/internal/models"
+)
+
+func TestRingKeepsTheLatestValues(t *testing.T) {
+	tests := []struct {
+		size, pushed int
+		want         string
+	}{
+		{3, 0, "[]"},
+		{3, 2, "[1 2]"},
+		{3, 3, "[1 2 3]"},
+		{3, 4, "[2 3 4]"},
+		{3, 7, "[5 6 7]"},
+		{3, 9, "[7 8 9]"},
+		{1, 5, "[5]"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d into %d", tt.pushed, tt.size), func(t *testing.T) {
+			r := newRing[int](tt.size)
+			for i := 1; i <= tt.pushed; i++ {
+				r.push(i)
+			}
+			if got := fmt.Sprint(r.values()); got != tt.want {
+				t.Errorf("values = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRingValuesAreACopy(t *testing.T) {
+	r := newRing[int](2)
+	r.push(1)
+	r.push(2)
+	values := r.values()
+	r.push(3)
+	if fmt.Sprint(values) != "[1 2]" {
+		t.Errorf("values changed to %v after a push", values)
+	}
+}
+
+var statusCodes = map[rune]models.HealthStatus{
+	'h': models.HealthStatusHealthy,
+	'd': models.HealthStatusDegraded,
+	'u': models.HealthStatusUnhealthy,
+}
+
+// statuses turns a compact history, such as "hdu", into samples
+func statuses(history string) []models.HealthSample {
+	samples := make([]models.HealthSample, 0, len(history))
+	for _, code := range history {
+		samples = append(samples, models.HealthSample{Status: statusCodes[code]})
+	}
+	return samples
+}
+
+// history turns samples back into their compact form
+func history(samples []models.HealthSample) string {
+	var codes []rune
+	for _, sample := range samples {
+		for code, status := range statusCodes {
+			if sample.Status == status {
+				codes = append(codes, code)
+			}
+		}
+	}
+	return string(codes)
+}
+
+func TestTransitions(t *testing.T) {
+	tests := []struct {
+		history string
+		want    int
+	}{
+		{"", 0},
+		{"h", 0},
+		{"hhhh", 0},
+		{"hu", 1},
+		{"hdu", 2},
+		{"huhu", 3},
+		{"hhuuhh", 2},
+	}
+	for _, tt := range tests {
+		if got := transitions(statuses(tt.history)); got != tt.want {
+			t.Errorf("transitions(%q) = %d, want %d", tt.history, got, tt.want)
+		}
+	}
+}
+
+// newHistoryService returns a health service for checker that keeps size
+// results and flaps at threshold transitions, fed by record rather than
+// a background probe
+func newHistoryService(checker Checker, size, threshold int) *healthService {
+	cfg := config.Default().Health
+	cfg.HistorySize = size
+	cfg.FlapThreshold = threshold
+	registry := NewHealthRegistry()
+	registry.Register(checker)
+	return NewHealthService(registry, NewStartupTracker(), "test", cfg).(*healthService)
+}
+
+func TestGetHistoryFlapping(t *testing.T) {
+	tests := []struct {
+		name        string
+		recorded    string
+		kept        string
+		transitions int
+		flapping    bool
+	}{
+		{"steady", "hhhhhhh", "hhhh", 0, false},
+		{"below threshold", "hhhuu", "hhuu", 1, false},
+		{"at threshold", "hhuhu", "huhu", 3, true},
+		// Older transitions have fallen out of the kept results
+		{"settled after flapping", "huhuhhhh", "hhhh", 0, false},
+		{"flapping across the wrap", "hhhhhuhdh", "uhdh", 3, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newHistoryService(&testChecker{name: "database"}, 4, 3)
+			start := time.Now().Add(-time.Hour)
+			for i, sample := range statuses(tt.recorded) {
+				s.record([]models.ComponentHealth{{
+					Name:      "database",
+					Status:    sample.Status,
+					CheckedAt: start.Add(time.Duration(i) * time.Second),
+				}})
+			}
+
+			response, err := s.GetHistory("database")
+			if err != nil {
+				t.Fatal(err)
+			}
+			entry := response.Components[0]
+			if got := history(entry.Samples); got != tt.kept {
+				t.Errorf("samples = %s, want %s", got, tt.kept)
+			}
+			for i := 1; i < len(entry.Samples); i++ {
+				if !entry.Samples[i].Timestamp.After(entry.Samples[i-1].Timestamp) {
+					t.Errorf("samples are not oldest first: %v", entry.Samples)
+				}
+			}
+			if entry.Transitions != tt.transitions || entry.Flapping != tt.flapping {
+				t.Errorf("transitions = %d, flapping = %v; want %d, %v",
+					entry.Transitions, entry.Flapping, tt.transitions, tt.flapping)
+			}
+			if want := statusCodes[rune(tt.recorded[len(tt.recorded)-1])]; entry.Status != want {
+				t.Errorf("status = %s, want the latest %s", entry.Status, want)
+			}
+		})
+	}
+}
+
+func TestGetHistoryUnknownComponent(t *testing.T) {
+	s := newHistoryService(&testChecker{name: "database"}, 4, 3)
+	if _, err := s.GetHistory("disk"); err != ErrUnknownComponent {
+		t.Errorf("got error %v, want ErrUnknownComponent", err)
+	}
+
+	// A component not checked yet has an empty history
+	response, err := s.GetHistory("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry := response.Components[0]; entry.Name != "database" || entry.Samples == nil || len(entry.Samples) != 0 {
+		t.Errorf("history = %+v, want database with no samples", entry)
+	}
+}
+
+func TestResultsMarkMissedChecksStale(t *testing.T) {
+	// A round of checks may take up to two intervals plus the timeout
+	checker := &testChecker{name: "database", timeout: time.Second}
+	interval := config.Default().Health.Interval
+	limit := 2*interval + checker.Timeout()
+
+	tests := []struct {
+		name  string
+		age   time.Duration
+		stale bool
+	}{
+		{"fresh", 0, false},
+		{"one missed round", interval + time.Second, false},
+		{"just within the limit", limit - time.Second, false},
+		{"past the limit", limit + time.Second, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newHistoryService(checker, 4, 3)
+			s.record([]models.ComponentHealth{{
+				Name:      "database",
+				Status:    models.HealthStatusHealthy,
+				CheckedAt: time.Now().Add(-tt.age),
+			}})
+
+			got, err := s.CheckComponent(context.Background(), "database")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Stale != tt.stale {
+				t.Errorf("stale = %v, want %v for a result %s old", got.Stale, tt.stale, tt.age)
+			}
+		})
+	}
+}
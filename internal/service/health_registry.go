@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"postService/internal/models"
+)
+
+// Checker checks one component of the service for the health endpoints
+type Checker interface {
+	// Name identifies the component in responses and in
+	// /health/component/:component
+	Name() string
+	// Critical components decide readiness; the others only affect /health
+	Critical() bool
+	// Timeout bounds Check. A check still running when it expires reports the
+	// component unhealthy.
+	Timeout() time.Duration
+	// Check reports the status of the component. Name and ResponseTime are
+	// filled in by the registry, and ctx carries the timeout.
+	Check(ctx context.Context) models.ComponentHealth
+}
+
+// HealthRegistry holds the checkers components register at startup, in the
+// order they are reported
+type HealthRegistry struct {
+	mutex    sync.RWMutex
+	checkers []Checker
+	byName   map[string]Checker
+}
+
+func NewHealthRegistry() *HealthRegistry {
+	return &HealthRegistry{byName: make(map[string]Checker)}
+}
+
+// Register adds checkers to the registry. Names must be unique, so
+// registering a name twice panics like a duplicate route would.
+func (r *HealthRegistry) Register(checkers ...Checker) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, checker := range checkers {
+		name := checker.Name()
+		if _, ok := r.byName[name]; ok {
+			panic(fmt.Sprintf("health checker %q registered twice", name))
+		}
+		r.byName[name] = checker
+		r.checkers = append(r.checkers, checker)
+	}
+}
+
+// Lookup returns the checker registered under name
+func (r *HealthRegistry) Lookup(name string) (Checker, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	checker, ok := r.byName[name]
+	return checker, ok
+}
+
+// Checkers returns every registered checker, or only the critical ones
+func (r *HealthRegistry) Checkers(criticalOnly bool) []Checker {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	checkers := make([]Checker, 0, len(r.checkers))
+	for _, checker := range r.checkers {
+		if checker.Critical() || !criticalOnly {
+			checkers = append(checkers, checker)
+		}
+	}
+	return checkers
+}
+
+// RunChecks runs checkers concurrently, each under its own timeout, and
+// returns their results in the same order
+func RunChecks(ctx context.Context, checkers []Checker) []models.ComponentHealth {
+	results := make([]models.ComponentHealth, len(checkers))
+
+	var wg sync.WaitGroup
+	for i, checker := range checkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = RunCheck(ctx, checker)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// RunCheck runs a single checker under its timeout. A check that panics or
+// overruns reports the component unhealthy; an overrunning check is left to
+// notice its cancelled context in the background.
+func RunCheck(ctx context.Context, checker Checker) models.ComponentHealth {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, checker.Timeout())
+	defer cancel()
+
+	done := make(chan models.ComponentHealth, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- models.ComponentHealth{
+					Status: models.HealthStatusUnhealthy,
+					Error:  fmt.Sprintf("Check panicked: %v", r),
+				}
+			}
+		}()
+		done <- checker.Check(ctx)
+	}()
+
+	var result models.ComponentHealth
+	select {
+	case result = <-done:
+	case <-ctx.Done():
+		result = models.ComponentHealth{
+			Status: models.HealthStatusUnhealthy,
+			Error:  fmt.Sprintf("Check did not finish within %s", checker.Timeout()),
+		}
+	}
+
+	result.Name = checker.Name()
+	result.ResponseTime = time.Since(start).Milliseconds()
+	result.CheckedAt = start
+	return result
+}
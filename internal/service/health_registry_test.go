@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"postService/internal/models"
+)
+
+// testChecker runs check, or reports healthy when it is nil
+type testChecker struct {
+	name     string
+	critical bool
+	timeout  time.Duration
+	check    func(ctx context.Context) models.ComponentHealth
+}
+
+func (c *testChecker) Name() string   { return c.name }
+func (c *testChecker) Critical() bool { return c.critical }
+
+func (c *testChecker) Timeout() time.Duration {
+	if c.timeout == 0 {
+		return time.Second
+	}
+	return c.timeout
+}
+
+func (c *testChecker) Check(ctx context.Context) models.ComponentHealth {
+	if c.check == nil {
+		return models.ComponentHealth{Status: models.HealthStatusHealthy}
+	}
+	return c.check(ctx)
+}
+
+func TestRegisterRejectsDuplicateNames(t *testing.T) {
+	registry := NewHealthRegistry()
+	registry.Register(&testChecker{name: "database"})
+
+	defer func() {
+		if r := recover(); r != `health checker "database" registered twice` {
+			t.Errorf("recovered %v, want a panic about the duplicate", r)
+		}
+		if got := registry.Checkers(false); len(got) != 1 {
+			t.Errorf("registry holds %d checkers after the duplicate, want 1", len(got))
+		}
+	}()
+	registry.Register(&testChecker{name: "database", critical: true})
+}
+
+func TestCheckersFiltersCritical(t *testing.T) {
+	registry := NewHealthRegistry()
+	registry.Register(
+		&testChecker{name: "database", critical: true},
+		&testChecker{name: "memory"},
+		&testChecker{name: "cache", critical: true},
+		&testChecker{name: "goroutines"},
+	)
+
+	names := func(checkers []Checker) []string {
+		var names []string
+		for _, checker := range checkers {
+			names = append(names, checker.Name())
+		}
+		return names
+	}
+	if got := fmt.Sprint(names(registry.Checkers(false))); got != "[database memory cache goroutines]" {
+		t.Errorf("all checkers = %s, want them in registration order", got)
+	}
+	if got := fmt.Sprint(names(registry.Checkers(true))); got != "[database cache]" {
+		t.Errorf("critical checkers = %s, want [database cache]", got)
+	}
+
+	if checker, ok := registry.Lookup("memory"); !ok || checker.Name() != "memory" {
+		t.Errorf("Lookup(memory) = %v, %v", checker, ok)
+	}
+	if _, ok := registry.Lookup("disk"); ok {
+		t.Error("Lookup found an unregistered checker")
+	}
+}
+
+func TestRunCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		checker *testChecker
+		status  models.HealthStatus
+		error   string
+	}{
+		{"healthy", &testChecker{name: "ok"}, models.HealthStatusHealthy, ""},
+		{"timeout", &testChecker{name: "slow", timeout: 20 * time.Millisecond, check: func(ctx context.Context) models.ComponentHealth {
+			<-ctx.Done()
+			time.Sleep(time.Second)
+			return models.ComponentHealth{Status: models.HealthStatusHealthy}
+		}}, models.HealthStatusUnhealthy, "Check did not finish within 20ms"},
+		{"panic", &testChecker{name: "broken", check: func(ctx context.Context) models.ComponentHealth {
+			panic("boom")
+		}}, models.HealthStatusUnhealthy, "Check panicked: boom"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := time.Now()
+			got := RunCheck(context.Background(), tt.checker)
+
+			if got.Status != tt.status || got.Error != tt.error {
+				t.Errorf("got %s %q, want %s %q", got.Status, got.Error, tt.status, tt.error)
+			}
+			if got.Name != tt.checker.name {
+				t.Errorf("name = %q, want %q", got.Name, tt.checker.name)
+			}
+			if got.CheckedAt.Before(before) || got.ResponseTime > tt.checker.Timeout().Milliseconds()+100 {
+				t.Errorf("checked at %v taking %dms, want it timed from the call", got.CheckedAt, got.ResponseTime)
+			}
+		})
+	}
+}
+
+func TestRunChecksRunsConcurrentlyInOrder(t *testing.T) {
+	// Every check waits for all of them to start, so they only finish
+	// before their timeout when they run at the same time
+	const count = 5
+	var started sync.WaitGroup
+	started.Add(count)
+
+	checkers := make([]Checker, count)
+	for i := range checkers {
+		checkers[i] = &testChecker{
+			name:    fmt.Sprintf("checker-%d", i),
+			timeout: time.Second,
+			check: func(ctx context.Context) models.ComponentHealth {
+				started.Done()
+				started.Wait()
+				// Finish in reverse order of registration
+				time.Sleep(time.Duration(count-i) * 5 * time.Millisecond)
+				return models.ComponentHealth{Status: models.HealthStatusHealthy, Message: fmt.Sprint(i)}
+			},
+		}
+	}
+
+	results := RunChecks(context.Background(), checkers)
+	for i, result := range results {
+		if result.Name != checkers[i].Name() || result.Message != fmt.Sprint(i) {
+			t.Errorf("result %d is %s (%q), want %s", i, result.Name, result.Message, checkers[i].Name())
+		}
+		if result.Status != models.HealthStatusHealthy {
+			t.Errorf("%s: %s %q, want healthy", result.Name, result.Status, result.Error)
+		}
+	}
+}
@@ -2,45 +2,178 @@ package service
 
 import (
 	"context"
-	"fmt"
-	"runtime"
+	"log/slog"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"postService/internal/database"
+	"postService/internal/config"
 	"postService/internal/models"
 )
 
 type HealthService interface {
-	GetHealth() *models.HealthResponse
+	GetHealth(ctx context.Context) *models.HealthResponse
 	GetLiveness() *models.LivenessResponse
-	GetReadiness() *models.ReadinessResponse
-	CheckComponent(name string) *models.ComponentHealth
+	GetReadiness(ctx context.Context) *models.ReadinessResponse
+	// GetStartup reports the progress of the boot phases
+	GetStartup() *models.StartupResponse
+	// CheckComponent returns the latest result of the component registered
+	// under name, or ErrUnknownComponent if there is none
+	CheckComponent(ctx context.Context, name string) (*models.ComponentHealth, error)
+	// GetHistory returns the recent results of every component, or only of
+	// the one named by component when it is not empty
+	GetHistory(component string) (*models.HealthHistoryResponse, error)
+	// Start checks every component once, then keeps checking them in the
+	// background until Stop is called
+	Start()
+	Stop()
+	// MarkShuttingDown makes readiness fail from now on, so load balancers
+	// stop routing new requests while in-flight ones finish
+	MarkShuttingDown()
 }
 
+// healthService answers from the results of a background probe, so probes
+// and dashboards polling the endpoints do not each run the checks, some of
+// which are expensive: reading memory stats stops the world
 type healthService struct {
-	db        *database.Database
-	startTime time.Time
-	version   string
+	registry     *HealthRegistry
+	startup      *StartupTracker
+	startTime    time.Time
+	version      string
+	config       config.HealthConfig
+	shuttingDown atomic.Bool
+
+	mutex  sync.RWMutex
+	states map[string]*componentState
+
+	// ctx is cancelled by Stop, which also aborts checks in progress
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+	started atomic.Bool
 }
 
-func NewHealthService(db *database.Database, version string) HealthService {
+// NewHealthService reports the components in registry. Readiness fails until
+// every phase tracked by startup is complete.
+func NewHealthService(registry *HealthRegistry, startup *StartupTracker, version string, cfg config.HealthConfig) HealthService {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &healthService{
-		db:        db,
+		registry:  registry,
+		startup:   startup,
 		startTime: time.Now(),
 		version:   version,
+		config:    cfg,
+		states:    make(map[string]*componentState),
+		ctx:       ctx,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+}
+
+func (s *healthService) Start() {
+	if s.started.CompareAndSwap(false, true) {
+		s.probe()
+		go s.run()
+	}
+}
+
+func (s *healthService) Stop() {
+	s.cancel()
+	if s.started.Load() {
+		<-s.done
+	}
+}
+
+func (s *healthService) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.probe()
+		}
+	}
+}
+
+// probe checks every component and records the results
+func (s *healthService) probe() {
+	results := RunChecks(s.ctx, s.registry.Checkers(false))
+	if s.ctx.Err() != nil {
+		return
+	}
+	s.record(results)
+}
+
+func (s *healthService) record(results []models.ComponentHealth) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, result := range results {
+		state, ok := s.states[result.Name]
+		if !ok {
+			state = &componentState{history: newRing[models.HealthSample](s.config.HistorySize)}
+			s.states[result.Name] = state
+		} else if state.latest.Status != result.Status {
+			slog.Warn("Health component changed status", "component", result.Name,
+				"from", state.latest.Status, "to", result.Status, "error", result.Error)
+		}
+
+		state.latest = result
+		state.history.push(models.HealthSample{
+			Timestamp:    result.CheckedAt,
+			Status:       result.Status,
+			ResponseTime: result.ResponseTime,
+			Error:        result.Error,
+		})
+	}
+}
+
+// results returns the latest result of each checker. Components the probe
+// has not reached yet, such as before Start, are checked on the spot.
+func (s *healthService) results(ctx context.Context, checkers []Checker) []models.ComponentHealth {
+	now := time.Now()
+	results := make([]models.ComponentHealth, len(checkers))
+	var missing []Checker
+
+	s.mutex.RLock()
+	for i, checker := range checkers {
+		state, ok := s.states[checker.Name()]
+		if !ok {
+			missing = append(missing, checker)
+			continue
+		}
+		results[i] = state.latest
+		// A round of checks takes at most as long as its slowest check, so a
+		// result older than two intervals plus its timeout was missed
+		results[i].Stale = now.Sub(state.latest.CheckedAt) > 2*s.config.Interval+checker.Timeout()
+	}
+	s.mutex.RUnlock()
+
+	if len(missing) == 0 {
+		return results
+	}
+	checked := RunChecks(ctx, missing)
+	s.record(checked)
+	for i, j := 0, 0; i < len(results); i++ {
+		if results[i].Name == "" {
+			results[i] = checked[j]
+			j++
+		}
 	}
+	return results
 }
 
-func (s *healthService) GetHealth() *models.HealthResponse {
+func (s *healthService) GetHealth(ctx context.Context) *models.HealthResponse {
 	timestamp := time.Now()
 	uptime := timestamp.Sub(s.startTime)
 	
-	// Check all components
-	components := []models.ComponentHealth{
-		*s.checkDatabase(),
-		*s.checkMemory(),
-		*s.checkGoroutines(),
-	}
+	// Report the latest results of all components
+	components := s.results(ctx, s.registry.Checkers(false))
 	
 	// Calculate summary
 	summary := s.calculateSummary(components)
@@ -67,14 +200,34 @@ func (s *healthService) GetLiveness() *models.LivenessResponse {
 	}
 }
 
-func (s *healthService) GetReadiness() *models.ReadinessResponse {
+func (s *healthService) MarkShuttingDown() {
+	s.shuttingDown.Store(true)
+}
+
+func (s *healthService) GetReadiness(ctx context.Context) *models.ReadinessResponse {
 	timestamp := time.Now()
 	
-	// Check critical components for readiness
-	components := []models.ComponentHealth{
-		*s.checkDatabase(),
+	if s.shuttingDown.Load() {
+		return &models.ReadinessResponse{
+			Status:     models.HealthStatusUnhealthy,
+			Timestamp:  timestamp,
+			Message:    "Service is shutting down",
+			Components: []models.ComponentHealth{},
+		}
+	}
+	
+	if phase := s.startup.Pending(); phase != "" {
+		return &models.ReadinessResponse{
+			Status:     models.HealthStatusUnhealthy,
+			Timestamp:  timestamp,
+			Message:    "Service is starting: " + phase,
+			Components: []models.ComponentHealth{},
+		}
 	}
 	
+	// Check critical components for readiness
+	components := s.results(ctx, s.registry.Checkers(true))
+	
 	// Determine readiness status
 	status := models.HealthStatusHealthy
 	message := "Service is ready to accept requests"
@@ -86,7 +239,7 @@ func (s *healthService) GetReadiness() *models.ReadinessResponse {
 			break
 		} else if comp.Status == models.HealthStatusDegraded && status == models.HealthStatusHealthy {
 			status = models.HealthStatusDegraded
-			message = "Service is partially ready - some components degraded"
+			message = "Service is ready - some components degraded"
 		}
 	}
 	
@@ -98,130 +251,53 @@ func (s *healthService) GetReadiness() *models.ReadinessResponse {
 	}
 }
 
-func (s *healthService) CheckComponent(name string) *models.ComponentHealth {
-	switch name {
-	case "database":
-		return s.checkDatabase()
-	case "memory":
-		return s.checkMemory()
-	case "goroutines":
-		return s.checkGoroutines()
-	default:
-		return &models.ComponentHealth{
-			Name:    name,
-			Status:  models.HealthStatusUnhealthy,
-			Message: "Unknown component",
-			Error:   "Component not found",
-		}
-	}
+func (s *healthService) GetStartup() *models.StartupResponse {
+	return s.startup.Report()
 }
 
-func (s *healthService) checkDatabase() *models.ComponentHealth {
-	start := time.Now()
-	component := &models.ComponentHealth{
-		Name:    "database",
-		Details: make(map[string]string),
+func (s *healthService) CheckComponent(ctx context.Context, name string) (*models.ComponentHealth, error) {
+	checker, ok := s.registry.Lookup(name)
+	if !ok {
+		return nil, ErrUnknownComponent
 	}
 	
-	if s.db == nil {
-		component.Status = models.HealthStatusUnhealthy
-		component.Error = "Database connection not initialized"
-		return component
-	}
-	
-	// Create a context with timeout for the health check
-	_, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	
-	// Check database health
-	if err := s.db.Health(); err != nil {
-		component.Status = models.HealthStatusUnhealthy
-		component.Error = fmt.Sprintf("Database health check failed: %v", err)
-		component.ResponseTime = int64(time.Since(start).Nanoseconds() / 1e6)
-		return component
-	}
-	
-	// Get database statistics
-	sqlDB, err := s.db.DB.DB()
-	if err == nil {
-		stats := sqlDB.Stats()
-		component.Details["open_connections"] = fmt.Sprintf("%d", stats.OpenConnections)
-		component.Details["in_use"] = fmt.Sprintf("%d", stats.InUse)
-		component.Details["idle"] = fmt.Sprintf("%d", stats.Idle)
-		component.Details["max_open"] = fmt.Sprintf("%d", stats.MaxOpenConnections)
-		
-		// Check if we're approaching connection limits
-		if stats.OpenConnections > int(float64(stats.MaxOpenConnections)*0.8) {
-			component.Status = models.HealthStatusDegraded
-			component.Message = "High connection usage detected"
-		} else {
-			component.Status = models.HealthStatusHealthy
-			component.Message = "Database connection healthy"
-		}
-	} else {
-		component.Status = models.HealthStatusDegraded
-		component.Message = "Could not get connection stats"
-		component.Error = err.Error()
-	}
-	
-	component.ResponseTime = int64(time.Since(start).Nanoseconds() / 1e6)
-	return component
+	component := s.results(ctx, []Checker{checker})[0]
+	return &component, nil
 }
 
-func (s *healthService) checkMemory() *models.ComponentHealth {
-	component := &models.ComponentHealth{
-		Name:    "memory",
-		Details: make(map[string]string),
+func (s *healthService) GetHistory(component string) (*models.HealthHistoryResponse, error) {
+	checkers := s.registry.Checkers(false)
+	if component != "" {
+		checker, ok := s.registry.Lookup(component)
+		if !ok {
+			return nil, ErrUnknownComponent
+		}
+		checkers = []Checker{checker}
 	}
 	
-	var m runtime.MemStats
-	runtime.ReadMemStats(&m)
-	
-	// Convert bytes to MB for readability
-	allocMB := m.Alloc / 1024 / 1024
-	sysMB := m.Sys / 1024 / 1024
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
 	
-	component.Details["alloc_mb"] = fmt.Sprintf("%d", allocMB)
-	component.Details["sys_mb"] = fmt.Sprintf("%d", sysMB)
-	component.Details["num_gc"] = fmt.Sprintf("%d", m.NumGC)
-	
-	// Simple memory usage assessment
-	if allocMB > 512 { // More than 512MB allocated
-		component.Status = models.HealthStatusDegraded
-		component.Message = "High memory usage detected"
-	} else if allocMB > 1024 { // More than 1GB allocated
-		component.Status = models.HealthStatusUnhealthy
-		component.Message = "Critical memory usage"
-	} else {
-		component.Status = models.HealthStatusHealthy
-		component.Message = "Memory usage normal"
+	history := &models.HealthHistoryResponse{
+		Timestamp:       time.Now(),
+		IntervalSeconds: s.config.Interval.Seconds(),
+		Components:      make([]models.ComponentHistory, 0, len(checkers)),
 	}
-	
-	return component
-}
-
-func (s *healthService) checkGoroutines() *models.ComponentHealth {
-	component := &models.ComponentHealth{
-		Name:    "goroutines",
-		Details: make(map[string]string),
-	}
-	
-	numGoroutines := runtime.NumGoroutine()
-	component.Details["count"] = fmt.Sprintf("%d", numGoroutines)
-	
-	// Assess goroutine count
-	if numGoroutines > 1000 {
-		component.Status = models.HealthStatusDegraded
-		component.Message = "High goroutine count detected"
-	} else if numGoroutines > 5000 {
-		component.Status = models.HealthStatusUnhealthy
-		component.Message = "Critical goroutine count"
-	} else {
-		component.Status = models.HealthStatusHealthy
-		component.Message = "Goroutine count normal"
+	for _, checker := range checkers {
+		entry := models.ComponentHistory{
+			Name:    checker.Name(),
+			Samples: []models.HealthSample{},
+		}
+		if state, ok := s.states[checker.Name()]; ok {
+			entry.Status = state.latest.Status
+			entry.Samples = state.history.values()
+			entry.Transitions = transitions(entry.Samples)
+			entry.Flapping = entry.Transitions >= s.config.FlapThreshold
+		}
+		history.Components = append(history.Components, entry)
 	}
 	
-	return component
+	return history, nil
 }
 
 func (s *healthService) calculateSummary(components []models.ComponentHealth) models.HealthSummary {
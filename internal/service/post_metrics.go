@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+
+	"postService/internal/models"
+)
+
+// PostMetrics counts successful post lifecycle events
+type PostMetrics interface {
+	PostCreated()
+	PostUpdated()
+	PostDeleted()
+}
+
+// instrumentedPostService counts the writes of the PostService it wraps
+type instrumentedPostService struct {
+	PostService
+	metrics PostMetrics
+}
+
+// InstrumentPostService wraps posts so that creates, updates and deletes are
+// reported to metrics once they succeed
+func InstrumentPostService(posts PostService, metrics PostMetrics) PostService {
+	return &instrumentedPostService{PostService: posts, metrics: metrics}
+}
+
+func (s *instrumentedPostService) CreatePost(ctx context.Context, req models.CreatePostRequest) (*models.Post, error) {
+	post, err := s.PostService.CreatePost(ctx, req)
+	if err == nil {
+		s.metrics.PostCreated()
+	}
+	return post, err
+}
+
+func (s *instrumentedPostService) UpdatePost(ctx context.Context, id string, req models.UpdatePostRequest, expectedVersion int) (*models.Post, error) {
+	post, err := s.PostService.UpdatePost(ctx, id, req, expectedVersion)
+	if err == nil {
+		s.metrics.PostUpdated()
+	}
+	return post, err
+}
+
+func (s *instrumentedPostService) DeletePost(ctx context.Context, id string, expectedVersion int) error {
+	err := s.PostService.DeletePost(ctx, id, expectedVersion)
+	if err == nil {
+		s.metrics.PostDeleted()
+	}
+	return err
+}
@@ -1,16 +1,38 @@
 package service
 
 import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
 	"postService/internal/models"
 	"postService/internal/repository"
 )
 
 type PostService interface {
-	CreatePost(req models.CreatePostRequest) (*models.Post, error)
-	GetPost(id string) (*models.Post, error)
-	GetAllPosts() ([]*models.Post, error)
-	UpdatePost(id string, req models.UpdatePostRequest) (*models.Post, error)
-	DeletePost(id string) error
+	CreatePost(ctx context.Context, req models.CreatePostRequest) (*models.Post, error)
+	// ImportPost stores a post exported from another instance, keeping its ID,
+	// timestamps and status. Its revision history starts over.
+	ImportPost(ctx context.Context, post *models.Post) error
+	GetPost(ctx context.Context, id string) (*models.Post, error)
+	GetPostBySlug(ctx context.Context, slug string) (*models.Post, error)
+	ListPosts(ctx context.Context, query models.PostQuery) (*models.PostPage, error)
+	SearchPosts(ctx context.Context, query models.SearchQuery) (*models.PostSearchResults, error)
+	UpdatePost(ctx context.Context, id string, req models.UpdatePostRequest, expectedVersion int) (*models.Post, error)
+	DeletePost(ctx context.Context, id string, expectedVersion int) error
+	RestorePost(ctx context.Context, id string) (*models.Post, error)
+	PurgePost(ctx context.Context, id string) error
+	ListRevisions(ctx context.Context, id string) ([]*models.PostRevision, error)
+	GetRevision(ctx context.Context, id string, revision int) (*models.PostRevision, error)
+	DiffRevisions(ctx context.Context, id string, from, to int) (*models.RevisionDiff, error)
+	RestoreRevision(ctx context.Context, id string, revision int, editor string, expectedVersion int) (*models.Post, error)
+	PublishPost(ctx context.Context, id string, req models.PublishPostRequest, expectedVersion int) (*models.Post, error)
+	UnpublishPost(ctx context.Context, id string, expectedVersion int) (*models.Post, error)
+	ArchivePost(ctx context.Context, id string, expectedVersion int) (*models.Post, error)
+	PublishDuePosts(ctx context.Context, now time.Time) (int64, error)
+	ListTags(ctx context.Context, limit int) ([]models.TagCount, error)
 }
 
 type postService struct {
@@ -21,27 +43,138 @@ func NewPostService(repo repository.PostRepository) PostService {
 	return &postService{repo: repo}
 }
 
-func (s *postService) CreatePost(req models.CreatePostRequest) (*models.Post, error) {
+func (s *postService) CreatePost(ctx context.Context, req models.CreatePostRequest) (*models.Post, error) {
 	post := models.NewPost(req)
-	err := s.repo.Create(post)
+	err := s.repo.Create(ctx, post)
 	if err != nil {
 		return nil, err
 	}
 	return post, nil
 }
 
-func (s *postService) GetPost(id string) (*models.Post, error) {
-	return s.repo.GetByID(id)
+func (s *postService) ImportPost(ctx context.Context, post *models.Post) error {
+	required := []struct{ field, value string }{
+		{"id", post.ID}, {"title", post.Title}, {"content", post.Content}, {"author", post.Author},
+	}
+	for _, r := range required {
+		if r.value == "" {
+			return NewValidationError(r.field, "is required")
+		}
+	}
+	if !post.Status.Valid() {
+		return NewValidationError("status", fmt.Sprintf("unknown status %q", post.Status))
+	}
+
+	tags := models.NormalizeTags(post.TagNames())
+	if err := models.ValidateTags(tags); err != nil {
+		return NewValidationError("tags", err.Error())
+	}
+
+	post.Tags = models.NewTags(tags)
+	post.Version = 1
+	post.DeletedAt = gorm.DeletedAt{}
+	return s.repo.Create(ctx, post)
+}
+
+func (s *postService) GetPost(ctx context.Context, id string) (*models.Post, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *postService) GetPostBySlug(ctx context.Context, slug string) (*models.Post, error) {
+	return s.repo.GetBySlug(ctx, slug)
+}
+
+func (s *postService) ListPosts(ctx context.Context, query models.PostQuery) (*models.PostPage, error) {
+	return s.repo.Find(ctx, query)
+}
+
+func (s *postService) SearchPosts(ctx context.Context, query models.SearchQuery) (*models.PostSearchResults, error) {
+	return s.repo.Search(ctx, query)
+}
+
+func (s *postService) UpdatePost(ctx context.Context, id string, req models.UpdatePostRequest, expectedVersion int) (*models.Post, error) {
+	req.Tags = models.NormalizeTags(req.Tags)
+	return s.repo.Update(ctx, id, req, expectedVersion)
+}
+
+func (s *postService) DeletePost(ctx context.Context, id string, expectedVersion int) error {
+	return s.repo.Delete(ctx, id, expectedVersion)
+}
+
+func (s *postService) RestorePost(ctx context.Context, id string) (*models.Post, error) {
+	return s.repo.Restore(ctx, id)
+}
+
+func (s *postService) PurgePost(ctx context.Context, id string) error {
+	return s.repo.Purge(ctx, id)
+}
+
+func (s *postService) ListRevisions(ctx context.Context, id string) ([]*models.PostRevision, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListRevisions(ctx, id)
+}
+
+func (s *postService) GetRevision(ctx context.Context, id string, revision int) (*models.PostRevision, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.GetRevision(ctx, id, revision)
 }
 
-func (s *postService) GetAllPosts() ([]*models.Post, error) {
-	return s.repo.GetAll()
+func (s *postService) DiffRevisions(ctx context.Context, id string, from, to int) (*models.RevisionDiff, error) {
+	fromRev, err := s.GetRevision(ctx, id, from)
+	if err != nil {
+		return nil, err
+	}
+	toRev, err := s.repo.GetRevision(ctx, id, to)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewRevisionDiff(fromRev, toRev), nil
 }
 
-func (s *postService) UpdatePost(id string, req models.UpdatePostRequest) (*models.Post, error) {
-	return s.repo.Update(id, req)
+// RestoreRevision rolls a post back to an earlier revision. The rollback is an
+// ordinary update, so it is recorded as a new revision rather than rewriting history.
+func (s *postService) RestoreRevision(ctx context.Context, id string, revision int, editor string, expectedVersion int) (*models.Post, error) {
+	rev, err := s.GetRevision(ctx, id, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.Update(ctx, id, models.UpdatePostRequest{
+		Title:   rev.Title,
+		Content: rev.Content,
+		Author:  rev.Author,
+		Editor:  editor,
+	}, expectedVersion)
+}
+
+// PublishPost publishes a post immediately, or schedules it when the request
+// carries a future publish time
+func (s *postService) PublishPost(ctx context.Context, id string, req models.PublishPostRequest, expectedVersion int) (*models.Post, error) {
+	status, publishAt := req.PublishTarget(time.Now())
+	return s.repo.Transition(ctx, id, status, publishAt, expectedVersion)
 }
 
-func (s *postService) DeletePost(id string) error {
-	return s.repo.Delete(id)
-}
\ No newline at end of file
+func (s *postService) UnpublishPost(ctx context.Context, id string, expectedVersion int) (*models.Post, error) {
+	return s.repo.Transition(ctx, id, models.PostStatusDraft, nil, expectedVersion)
+}
+
+func (s *postService) ArchivePost(ctx context.Context, id string, expectedVersion int) (*models.Post, error) {
+	post, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// Archived posts keep their original publish time, if any
+	return s.repo.Transition(ctx, id, models.PostStatusArchived, post.PublishAt, expectedVersion)
+}
+
+func (s *postService) PublishDuePosts(ctx context.Context, now time.Time) (int64, error) {
+	return s.repo.PublishDue(ctx, now)
+}
+
+func (s *postService) ListTags(ctx context.Context, limit int) ([]models.TagCount, error) {
+	return s.repo.ListTags(ctx, limit)
+}
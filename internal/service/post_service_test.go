@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"postService/internal/models"
+	"postService/internal/repository"
+)
+
+func newTestPostService() PostService {
+	return NewPostService(repository.NewInMemoryPostRepository())
+}
+
+func createTestPost(t *testing.T, posts PostService, title string) *models.Post {
+	t.Helper()
+	post, err := posts.CreatePost(context.Background(), models.CreatePostRequest{
+		Title:   title,
+		Content: "content of " + title,
+		Author:  "ann",
+		Status:  models.PostStatusPublished,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return post
+}
+
+// assertKind fails unless err is a DomainError of the given kind
+func assertKind(t *testing.T, err error, kind error, message string) {
+	t.Helper()
+	var domainErr *DomainError
+	if !errors.As(err, &domainErr) || !errors.Is(err, kind) || domainErr.Message != message {
+		t.Fatalf("got error %v, want %q of kind %v", err, message, kind)
+	}
+}
+
+func listTrash(t *testing.T, posts PostService) []*models.Post {
+	t.Helper()
+	page, err := posts.ListPosts(context.Background(), models.PostQuery{
+		Deleted: true,
+		Page:    models.PageRequest{Limit: models.DefaultPageLimit},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return page.Data
+}
+
+func TestDeletePostMovesItToTrash(t *testing.T) {
+	ctx := context.Background()
+	posts := newTestPostService()
+	kept := createTestPost(t, posts, "kept")
+	trashed := createTestPost(t, posts, "trashed")
+
+	if err := posts.DeletePost(ctx, trashed.ID, trashed.Version); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := posts.GetPost(ctx, trashed.ID)
+	assertKind(t, err, ErrNotFound, "post not found")
+	_, err = posts.GetPostBySlug(ctx, trashed.Slug)
+	assertKind(t, err, ErrNotFound, "post not found")
+
+	page, err := posts.ListPosts(ctx, models.PostQuery{Page: models.PageRequest{Limit: models.DefaultPageLimit}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Data) != 1 || page.Data[0].ID != kept.ID {
+		t.Errorf("live listing = %v, want only %s", page.Data, kept.ID)
+	}
+
+	trash := listTrash(t, posts)
+	if len(trash) != 1 || trash[0].ID != trashed.ID || !trash[0].DeletedAt.Valid {
+		t.Errorf("trash = %v, want only %s with deleted_at set", trash, trashed.ID)
+	}
+
+	// A post already in the trash cannot be deleted again
+	assertKind(t, posts.DeletePost(ctx, trashed.ID, 0), ErrNotFound, "post not found")
+}
+
+func TestDeletePostChecksVersion(t *testing.T) {
+	ctx := context.Background()
+	posts := newTestPostService()
+	post := createTestPost(t, posts, "versioned")
+
+	err := posts.DeletePost(ctx, post.ID, post.Version+1)
+	if !errors.Is(err, ErrVersionMismatch) || !errors.Is(err, ErrConflict) {
+		t.Fatalf("got error %v, want ErrVersionMismatch", err)
+	}
+	if len(listTrash(t, posts)) != 0 {
+		t.Error("post was trashed despite the version mismatch")
+	}
+}
+
+func TestRestorePostBringsItBack(t *testing.T) {
+	ctx := context.Background()
+	posts := newTestPostService()
+	post := createTestPost(t, posts, "restored")
+	version := post.Version
+
+	if err := posts.DeletePost(ctx, post.ID, version); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := posts.RestorePost(ctx, post.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.DeletedAt.Valid {
+		t.Error("restored post still has deleted_at set")
+	}
+	// Restoring is a write, so clients holding the old ETag must re-read
+	if restored.Version != version+1 {
+		t.Errorf("restored version = %d, want %d", restored.Version, version+1)
+	}
+
+	got, err := posts.GetPost(ctx, post.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Version != restored.Version || got.Title != "restored" || got.Slug != post.Slug {
+		t.Errorf("got %+v after restore, want version %d, title and slug unchanged", got, restored.Version)
+	}
+	if len(listTrash(t, posts)) != 0 {
+		t.Error("restored post is still in the trash")
+	}
+
+	_, err = posts.RestorePost(ctx, post.ID)
+	assertKind(t, err, ErrNotFound, "post not found in trash")
+}
+
+func TestPurgePostOnlyFromTrash(t *testing.T) {
+	ctx := context.Background()
+	posts := newTestPostService()
+	post := createTestPost(t, posts, "purged")
+
+	assertKind(t, posts.PurgePost(ctx, post.ID), ErrNotFound, "post not found in trash")
+	if _, err := posts.GetPost(ctx, post.ID); err != nil {
+		t.Fatalf("live post is gone after a refused purge: %v", err)
+	}
+
+	if err := posts.DeletePost(ctx, post.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := posts.PurgePost(ctx, post.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(listTrash(t, posts)) != 0 {
+		t.Error("purged post is still in the trash")
+	}
+	_, err := posts.RestorePost(ctx, post.ID)
+	assertKind(t, err, ErrNotFound, "post not found in trash")
+	assertKind(t, posts.PurgePost(ctx, post.ID), ErrNotFound, "post not found in trash")
+
+	// The purged post's slug is free again
+	again := createTestPost(t, posts, "purged")
+	if again.Slug != post.Slug {
+		t.Errorf("slug = %q, want %q reused", again.Slug, post.Slug)
+	}
+}
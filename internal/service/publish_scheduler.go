@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// PublishScheduler periodically promotes scheduled posts whose publish time
+// has passed. Every replica may run one: publishing is a single conditional
+// update, so a post is never published twice.
+type PublishScheduler struct {
+	posts    PostService
+	interval time.Duration
+	// ctx is cancelled by Stop, which also aborts a run in progress
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+	started atomic.Bool
+}
+
+func NewPublishScheduler(posts PostService, interval time.Duration) *PublishScheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &PublishScheduler{
+		posts:    posts,
+		interval: interval,
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+}
+
+// Start runs the scheduler in a background goroutine until Stop is called
+func (s *PublishScheduler) Start() {
+	if s.started.CompareAndSwap(false, true) {
+		go s.run()
+	}
+}
+
+// Stop cancels the current run, if any, and waits for the scheduler to exit
+func (s *PublishScheduler) Stop() {
+	s.cancel()
+	if s.started.Load() {
+		<-s.done
+	}
+}
+
+func (s *PublishScheduler) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.publishDue()
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *PublishScheduler) publishDue() {
+	published, err := s.posts.PublishDuePosts(s.ctx, time.Now())
+	if err != nil {
+		if s.ctx.Err() != nil {
+			return
+		}
+		slog.Error("Failed to publish scheduled posts", "error", err)
+		return
+	}
+	if published > 0 {
+		slog.Info("Published scheduled posts", "count", published)
+	}
+}
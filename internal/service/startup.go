@@ -0,0 +1,108 @@
+package service
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"postService/internal/models"
+)
+
+// StartupTracker records the progress of the phases the service goes
+// through while booting, for the startup probe and readiness
+type StartupTracker struct {
+	mutex  sync.RWMutex
+	phases []models.StartupPhase
+}
+
+// NewStartupTracker tracks the named phases, which run in the given order
+func NewStartupTracker(phases ...string) *StartupTracker {
+	t := &StartupTracker{}
+	for _, name := range phases {
+		t.phases = append(t.phases, models.StartupPhase{Name: name, Status: models.StartupPhasePending})
+	}
+	return t
+}
+
+// Run runs fn as the named phase, recording when it starts and how it ends
+func (t *StartupTracker) Run(name string, fn func() error) error {
+	t.update(name, func(phase *models.StartupPhase, now time.Time) {
+		phase.Status = models.StartupPhaseRunning
+		phase.StartedAt = &now
+	})
+	slog.Info("Startup phase started", "phase", name)
+
+	if err := fn(); err != nil {
+		t.update(name, func(phase *models.StartupPhase, now time.Time) {
+			phase.Status = models.StartupPhaseFailed
+			phase.Error = err.Error()
+		})
+		return err
+	}
+
+	t.update(name, func(phase *models.StartupPhase, now time.Time) {
+		phase.Status = models.StartupPhaseComplete
+		phase.CompletedAt = &now
+		phase.Error = ""
+	})
+	slog.Info("Startup phase complete", "phase", name)
+	return nil
+}
+
+// Attempt records a try of a phase that is retried, and its error if it failed
+func (t *StartupTracker) Attempt(name string, err error) {
+	t.update(name, func(phase *models.StartupPhase, now time.Time) {
+		phase.Attempts++
+		if err != nil {
+			phase.Error = err.Error()
+		}
+	})
+}
+
+func (t *StartupTracker) update(name string, change func(phase *models.StartupPhase, now time.Time)) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for i := range t.phases {
+		if t.phases[i].Name == name {
+			change(&t.phases[i], time.Now())
+			return
+		}
+	}
+	panic("unknown startup phase " + name)
+}
+
+// Pending returns the first phase that has not completed, or "" once the
+// service has started
+func (t *StartupTracker) Pending() string {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	for _, phase := range t.phases {
+		if phase.Status != models.StartupPhaseComplete {
+			return phase.Name
+		}
+	}
+	return ""
+}
+
+func (t *StartupTracker) Report() *models.StartupResponse {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	report := &models.StartupResponse{
+		Status:    models.StartupStatusStarted,
+		Timestamp: time.Now(),
+		Phases:    append([]models.StartupPhase(nil), t.phases...),
+	}
+	for _, phase := range t.phases {
+		switch phase.Status {
+		case models.StartupPhaseFailed:
+			report.Status = models.StartupStatusFailed
+			return report
+		case models.StartupPhasePending, models.StartupPhaseRunning:
+			report.Status = models.StartupStatusStarting
+		}
+	}
+	return report
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"postService/internal/config"
+	"postService/internal/models"
+)
+
+// phaseStatuses lists the status of every phase in a report
+func phaseStatuses(report *models.StartupResponse) []models.StartupPhaseStatus {
+	var statuses []models.StartupPhaseStatus
+	for _, phase := range report.Phases {
+		statuses = append(statuses, phase.Status)
+	}
+	return statuses
+}
+
+func TestStartupTrackerTransitions(t *testing.T) {
+	tracker := NewStartupTracker("database", "migrations")
+	assertReport := func(status models.StartupStatus, pending string, phases ...models.StartupPhaseStatus) {
+		t.Helper()
+		report := tracker.Report()
+		if report.Status != status {
+			t.Errorf("status = %s, want %s", report.Status, status)
+		}
+		if got := tracker.Pending(); got != pending {
+			t.Errorf("pending = %q, want %q", got, pending)
+		}
+		got := phaseStatuses(report)
+		for i := range phases {
+			if i >= len(got) || got[i] != phases[i] {
+				t.Errorf("phases = %v, want %v", got, phases)
+				break
+			}
+		}
+	}
+
+	assertReport(models.StartupStatusStarting, "database", models.StartupPhasePending, models.StartupPhasePending)
+
+	err := tracker.Run("database", func() error {
+		assertReport(models.StartupStatusStarting, "database", models.StartupPhaseRunning, models.StartupPhasePending)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertReport(models.StartupStatusStarting, "migrations", models.StartupPhaseComplete, models.StartupPhasePending)
+	if phase := tracker.Report().Phases[0]; phase.StartedAt == nil || phase.CompletedAt == nil || phase.CompletedAt.Before(*phase.StartedAt) {
+		t.Errorf("database phase = %+v, want it timed", phase)
+	}
+
+	failure := errors.New("lock timeout")
+	if err := tracker.Run("migrations", func() error { return failure }); err != failure {
+		t.Fatalf("Run returned %v, want the phase's error", err)
+	}
+	assertReport(models.StartupStatusFailed, "migrations", models.StartupPhaseComplete, models.StartupPhaseFailed)
+	if phase := tracker.Report().Phases[1]; phase.Error != "lock timeout" || phase.CompletedAt != nil {
+		t.Errorf("migrations phase = %+v, want it failed with the error", phase)
+	}
+
+	// A phase run again after failing can still complete
+	if err := tracker.Run("migrations", func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	assertReport(models.StartupStatusStarted, "", models.StartupPhaseComplete, models.StartupPhaseComplete)
+	if phase := tracker.Report().Phases[1]; phase.Error != "" {
+		t.Errorf("migrations error = %q after completing, want it cleared", phase.Error)
+	}
+}
+
+func TestStartupTrackerAttempts(t *testing.T) {
+	tracker := NewStartupTracker("database")
+	tracker.Attempt("database", errors.New("connection refused"))
+	tracker.Attempt("database", errors.New("no route to host"))
+	tracker.Attempt("database", nil)
+
+	phase := tracker.Report().Phases[0]
+	if phase.Attempts != 3 || phase.Error != "no route to host" {
+		t.Errorf("phase = %+v, want 3 attempts and the latest error", phase)
+	}
+	if phase.Status != models.StartupPhasePending {
+		t.Errorf("status = %s, want attempts to leave it pending", phase.Status)
+	}
+}
+
+func TestStartupTrackerRejectsUnknownPhases(t *testing.T) {
+	defer func() {
+		if r := recover(); r != "unknown startup phase cache" {
+			t.Errorf("recovered %v, want a panic naming the phase", r)
+		}
+	}()
+	NewStartupTracker("database").Run("cache", func() error { return nil })
+}
+
+func TestReadinessWaitsForStartup(t *testing.T) {
+	var checks atomic.Int32
+	registry := NewHealthRegistry()
+	registry.Register(&testChecker{name: "database", critical: true, check: func(ctx context.Context) models.ComponentHealth {
+		checks.Add(1)
+		return models.ComponentHealth{Status: models.HealthStatusHealthy}
+	}})
+	tracker := NewStartupTracker("database", "warmup")
+	health := NewHealthService(registry, tracker, "test", config.Default().Health)
+
+	for _, pending := range []string{"database", "warmup"} {
+		readiness := health.GetReadiness(context.Background())
+		if readiness.Status != models.HealthStatusUnhealthy || readiness.Message != "Service is starting: "+pending {
+			t.Errorf("readiness = %s %q, want unhealthy while %s is pending", readiness.Status, readiness.Message, pending)
+		}
+		tracker.Run(pending, func() error { return nil })
+	}
+	if checks.Load() != 0 {
+		t.Errorf("checked components %d times while starting, want none", checks.Load())
+	}
+
+	readiness := health.GetReadiness(context.Background())
+	if readiness.Status != models.HealthStatusHealthy || len(readiness.Components) != 1 {
+		t.Errorf("readiness = %s with %d components, want healthy once started", readiness.Status, len(readiness.Components))
+	}
+}
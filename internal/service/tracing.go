@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+
+	"postService/internal/models"
+	"postService/internal/tracing"
+)
+
+var tracer = otel.Tracer("postService/internal/service")
+
+// tracedPostService runs every call of the PostService it wraps in a span
+type tracedPostService struct {
+	posts PostService
+}
+
+// TracePostService wraps posts so that each call shows up as a span named
+// after the method, between the request span and the repository spans
+func TracePostService(posts PostService) PostService {
+	return &tracedPostService{posts: posts}
+}
+
+func (s *tracedPostService) CreatePost(ctx context.Context, req models.CreatePostRequest) (*models.Post, error) {
+	return tracing.Run(ctx, tracer, "PostService.CreatePost", func(ctx context.Context) (*models.Post, error) {
+		return s.posts.CreatePost(ctx, req)
+	})
+}
+
+func (s *tracedPostService) ImportPost(ctx context.Context, post *models.Post) error {
+	return tracing.RunErr(ctx, tracer, "PostService.ImportPost", func(ctx context.Context) error {
+		return s.posts.ImportPost(ctx, post)
+	}, tracing.PostID(post.ID))
+}
+
+func (s *tracedPostService) GetPost(ctx context.Context, id string) (*models.Post, error) {
+	return tracing.Run(ctx, tracer, "PostService.GetPost", func(ctx context.Context) (*models.Post, error) {
+		return s.posts.GetPost(ctx, id)
+	}, tracing.PostID(id))
+}
+
+func (s *tracedPostService) GetPostBySlug(ctx context.Context, slug string) (*models.Post, error) {
+	return tracing.Run(ctx, tracer, "PostService.GetPostBySlug", func(ctx context.Context) (*models.Post, error) {
+		return s.posts.GetPostBySlug(ctx, slug)
+	})
+}
+
+func (s *tracedPostService) ListPosts(ctx context.Context, query models.PostQuery) (*models.PostPage, error) {
+	return tracing.Run(ctx, tracer, "PostService.ListPosts", func(ctx context.Context) (*models.PostPage, error) {
+		return s.posts.ListPosts(ctx, query)
+	})
+}
+
+func (s *tracedPostService) SearchPosts(ctx context.Context, query models.SearchQuery) (*models.PostSearchResults, error) {
+	return tracing.Run(ctx, tracer, "PostService.SearchPosts", func(ctx context.Context) (*models.PostSearchResults, error) {
+		return s.posts.SearchPosts(ctx, query)
+	})
+}
+
+func (s *tracedPostService) UpdatePost(ctx context.Context, id string, req models.UpdatePostRequest, expectedVersion int) (*models.Post, error) {
+	return tracing.Run(ctx, tracer, "PostService.UpdatePost", func(ctx context.Context) (*models.Post, error) {
+		return s.posts.UpdatePost(ctx, id, req, expectedVersion)
+	}, tracing.PostID(id))
+}
+
+func (s *tracedPostService) DeletePost(ctx context.Context, id string, expectedVersion int) error {
+	return tracing.RunErr(ctx, tracer, "PostService.DeletePost", func(ctx context.Context) error {
+		return s.posts.DeletePost(ctx, id, expectedVersion)
+	}, tracing.PostID(id))
+}
+
+func (s *tracedPostService) RestorePost(ctx context.Context, id string) (*models.Post, error) {
+	return tracing.Run(ctx, tracer, "PostService.RestorePost", func(ctx context.Context) (*models.Post, error) {
+		return s.posts.RestorePost(ctx, id)
+	}, tracing.PostID(id))
+}
+
+func (s *tracedPostService) PurgePost(ctx context.Context, id string) error {
+	return tracing.RunErr(ctx, tracer, "PostService.PurgePost", func(ctx context.Context) error {
+		return s.posts.PurgePost(ctx, id)
+	}, tracing.PostID(id))
+}
+
+func (s *tracedPostService) ListRevisions(ctx context.Context, id string) ([]*models.PostRevision, error) {
+	return tracing.Run(ctx, tracer, "PostService.ListRevisions", func(ctx context.Context) ([]*models.PostRevision, error) {
+		return s.posts.ListRevisions(ctx, id)
+	}, tracing.PostID(id))
+}
+
+func (s *tracedPostService) GetRevision(ctx context.Context, id string, revision int) (*models.PostRevision, error) {
+	return tracing.Run(ctx, tracer, "PostService.GetRevision", func(ctx context.Context) (*models.PostRevision, error) {
+		return s.posts.GetRevision(ctx, id, revision)
+	}, tracing.PostID(id))
+}
+
+func (s *tracedPostService) DiffRevisions(ctx context.Context, id string, from, to int) (*models.RevisionDiff, error) {
+	return tracing.Run(ctx, tracer, "PostService.DiffRevisions", func(ctx context.Context) (*models.RevisionDiff, error) {
+		return s.posts.DiffRevisions(ctx, id, from, to)
+	}, tracing.PostID(id))
+}
+
+func (s *tracedPostService) RestoreRevision(ctx context.Context, id string, revision int, editor string, expectedVersion int) (*models.Post, error) {
+	return tracing.Run(ctx, tracer, "PostService.RestoreRevision", func(ctx context.Context) (*models.Post, error) {
+		return s.posts.RestoreRevision(ctx, id, revision, editor, expectedVersion)
+	}, tracing.PostID(id))
+}
+
+func (s *tracedPostService) PublishPost(ctx context.Context, id string, req models.PublishPostRequest, expectedVersion int) (*models.Post, error) {
+	return tracing.Run(ctx, tracer, "PostService.PublishPost", func(ctx context.Context) (*models.Post, error) {
+		return s.posts.PublishPost(ctx, id, req, expectedVersion)
+	}, tracing.PostID(id))
+}
+
+func (s *tracedPostService) UnpublishPost(ctx context.Context, id string, expectedVersion int) (*models.Post, error) {
+	return tracing.Run(ctx, tracer, "PostService.UnpublishPost", func(ctx context.Context) (*models.Post, error) {
+		return s.posts.UnpublishPost(ctx, id, expectedVersion)
+	}, tracing.PostID(id))
+}
+
+func (s *tracedPostService) ArchivePost(ctx context.Context, id string, expectedVersion int) (*models.Post, error) {
+	return tracing.Run(ctx, tracer, "PostService.ArchivePost", func(ctx context.Context) (*models.Post, error) {
+		return s.posts.ArchivePost(ctx, id, expectedVersion)
+	}, tracing.PostID(id))
+}
+
+// PublishDuePosts runs from the scheduler rather than a request, so each run
+// is the root of a trace of its own
+func (s *tracedPostService) PublishDuePosts(ctx context.Context, now time.Time) (int64, error) {
+	return tracing.Run(ctx, tracer, "PostService.PublishDuePosts", func(ctx context.Context) (int64, error) {
+		return s.posts.PublishDuePosts(ctx, now)
+	})
+}
+
+func (s *tracedPostService) ListTags(ctx context.Context, limit int) ([]models.TagCount, error) {
+	return tracing.Run(ctx, tracer, "PostService.ListTags", func(ctx context.Context) ([]models.TagCount, error) {
+		return s.posts.ListTags(ctx, limit)
+	})
+}
+
+// tracedCommentService runs every call of the CommentService it wraps in a span
+type tracedCommentService struct {
+	comments CommentService
+}
+
+// TraceCommentService wraps comments so that each call shows up as a span
+func TraceCommentService(comments CommentService) CommentService {
+	return &tracedCommentService{comments: comments}
+}
+
+func (s *tracedCommentService) CreateComment(ctx context.Context, postID string, req models.CreateCommentRequest) (*models.Comment, error) {
+	return tracing.Run(ctx, tracer, "CommentService.CreateComment", func(ctx context.Context) (*models.Comment, error) {
+		return s.comments.CreateComment(ctx, postID, req)
+	}, tracing.PostID(postID))
+}
+
+func (s *tracedCommentService) GetComment(ctx context.Context, postID, id string) (*models.Comment, error) {
+	return tracing.Run(ctx, tracer, "CommentService.GetComment", func(ctx context.Context) (*models.Comment, error) {
+		return s.comments.GetComment(ctx, postID, id)
+	}, tracing.PostID(postID), tracing.CommentID(id))
+}
+
+func (s *tracedCommentService) ListComments(ctx context.Context, query models.CommentQuery) (*models.CommentPage, error) {
+	return tracing.Run(ctx, tracer, "CommentService.ListComments", func(ctx context.Context) (*models.CommentPage, error) {
+		return s.comments.ListComments(ctx, query)
+	}, tracing.PostID(query.PostID))
+}
+
+func (s *tracedCommentService) UpdateComment(ctx context.Context, postID, id string, req models.UpdateCommentRequest) (*models.Comment, error) {
+	return tracing.Run(ctx, tracer, "CommentService.UpdateComment", func(ctx context.Context) (*models.Comment, error) {
+		return s.comments.UpdateComment(ctx, postID, id, req)
+	}, tracing.PostID(postID), tracing.CommentID(id))
+}
+
+func (s *tracedCommentService) DeleteComment(ctx context.Context, postID, id string) error {
+	return tracing.RunErr(ctx, tracer, "CommentService.DeleteComment", func(ctx context.Context) error {
+		return s.comments.DeleteComment(ctx, postID, id)
+	}, tracing.PostID(postID), tracing.CommentID(id))
+}
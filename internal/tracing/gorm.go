@@ -0,0 +1,84 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const gormSpanKey = "tracing:span"
+
+// GormPlugin records every statement GORM runs as a client span, a child of
+// whatever span is in the statement's context (see gorm.DB.WithContext). The
+// SQL is recorded with placeholders, never with its arguments.
+type GormPlugin struct{}
+
+func NewGormPlugin() *GormPlugin {
+	return &GormPlugin{}
+}
+
+func (p *GormPlugin) Name() string {
+	return "tracing"
+}
+
+func (p *GormPlugin) Initialize(db *gorm.DB) error {
+	tracer := otel.Tracer(instrumentationName)
+
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("tracing:before_create", before(tracer, "create")),
+		cb.Create().After("gorm:create").Register("tracing:after_create", after),
+		cb.Query().Before("gorm:query").Register("tracing:before_query", before(tracer, "query")),
+		cb.Query().After("gorm:query").Register("tracing:after_query", after),
+		cb.Update().Before("gorm:update").Register("tracing:before_update", before(tracer, "update")),
+		cb.Update().After("gorm:update").Register("tracing:after_update", after),
+		cb.Delete().Before("gorm:delete").Register("tracing:before_delete", before(tracer, "delete")),
+		cb.Delete().After("gorm:delete").Register("tracing:after_delete", after),
+		cb.Row().Before("gorm:row").Register("tracing:before_row", before(tracer, "row")),
+		cb.Row().After("gorm:row").Register("tracing:after_row", after),
+		cb.Raw().Before("gorm:raw").Register("tracing:before_raw", before(tracer, "raw")),
+		cb.Raw().After("gorm:raw").Register("tracing:after_raw", after),
+	)
+}
+
+func before(tracer trace.Tracer, operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if !trace.SpanFromContext(ctx).SpanContext().IsValid() {
+			// Statements outside of any trace, such as migrations, stay untraced
+			return
+		}
+
+		_, span := tracer.Start(ctx, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemPostgreSQL),
+		)
+		db.InstanceSet(gormSpanKey, span)
+	}
+}
+
+func after(db *gorm.DB) {
+	value, ok := db.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span := value.(trace.Span)
+	defer span.End()
+
+	span.SetAttributes(
+		semconv.DBQueryText(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.RowsAffected),
+	)
+	if db.Statement.Table != "" {
+		span.SetAttributes(semconv.DBCollectionName(db.Statement.Table))
+	}
+	if err := db.Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
@@ -0,0 +1,54 @@
+package tracing
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware starts a server span for every request, continuing the trace
+// of the caller when the request carries a W3C traceparent header. The span
+// is named after the route template, and handlers further down find it in
+// the request context.
+func Middleware() gin.HandlerFunc {
+	tracer := otel.Tracer(instrumentationName)
+
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		name := c.Request.Method
+		if route != "" {
+			name += " " + route
+		}
+
+		ctx, span := tracer.Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.URLPath(c.Request.URL.Path),
+			),
+		)
+		defer span.End()
+		if route != "" {
+			span.SetAttributes(semconv.HTTPRoute(route))
+		}
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= 500 {
+			span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", status))
+		}
+		for _, err := range c.Errors {
+			span.RecordError(err.Err)
+		}
+	}
+}
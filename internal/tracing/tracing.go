@@ -0,0 +1,114 @@
+// Package tracing sets up OpenTelemetry tracing and instruments the layers
+// a request passes through: the gin router, services, repositories and SQL.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"postService/internal/config"
+)
+
+// ServiceName identifies the service in exported spans
+const ServiceName = "post-service"
+
+// instrumentationName names the tracer used by this package's instrumentation
+const instrumentationName = "postService/internal/tracing"
+
+// Setup installs the global tracer provider for the configured exporter and
+// the W3C trace context propagator. The returned function flushes pending
+// spans and must be called before exiting. With the none exporter a no-op
+// provider is installed, so nothing is recorded or sampled.
+func Setup(ctx context.Context, cfg config.TracingConfig, version string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Exporter {
+	case "none":
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		return func(context.Context) error { return nil }, nil
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case "otlp":
+		var opts []otlptracehttp.Option
+		if cfg.OTLPEndpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(cfg.OTLPEndpoint))
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	default:
+		err = fmt.Errorf("unknown exporter %q", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(ServiceName),
+		semconv.ServiceVersion(version),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Run calls fn inside a span named name, recording the error it returns
+func Run[T any](ctx context.Context, tracer trace.Tracer, name string, fn func(context.Context) (T, error), attrs ...attribute.KeyValue) (T, error) {
+	ctx, span := tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+	defer span.End()
+
+	result, err := fn(ctx)
+	recordError(span, err)
+	return result, err
+}
+
+// RunErr is Run for functions that only return an error
+func RunErr(ctx context.Context, tracer trace.Tracer, name string, fn func(context.Context) error, attrs ...attribute.KeyValue) error {
+	ctx, span := tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+	defer span.End()
+
+	err := fn(ctx)
+	recordError(span, err)
+	return err
+}
+
+// PostID tags a span with the post it concerns
+func PostID(id string) attribute.KeyValue {
+	return attribute.String("post.id", id)
+}
+
+// CommentID tags a span with the comment it concerns
+func CommentID(id string) attribute.KeyValue {
+	return attribute.String("comment.id", id)
+}
+
+func recordError(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
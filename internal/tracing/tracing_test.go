@@ -0,0 +1,246 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"postService/internal/config"
+	"postService/internal/handlers"
+	"postService/internal/middleware"
+	"postService/internal/repository"
+	"postService/internal/service"
+	"postService/internal/tracing"
+)
+
+// recorder collects the spans of every test. Tracers created before a
+// provider is installed stick to the first one, so all tests share it and
+// tell their spans apart by trace ID.
+var recorder = tracetest.NewSpanRecorder()
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	os.Exit(m.Run())
+}
+
+// spansOf returns the ended spans of a trace by name
+func spansOf(traceID trace.TraceID) map[string]sdktrace.ReadOnlySpan {
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		if span.SpanContext().TraceID() == traceID {
+			spans[span.Name()] = span
+		}
+	}
+	return spans
+}
+
+func attr(span sdktrace.ReadOnlySpan, key attribute.Key) string {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value.Emit()
+		}
+	}
+	return ""
+}
+
+// request serves a GET for path with a traceparent header continuing the
+// given trace and parent span
+func request(router http.Handler, path string, traceID trace.TraceID, parent trace.SpanID) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("traceparent", "00-"+traceID.String()+"-"+parent.String()+"-01")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestMiddlewareContinuesIncomingTrace(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	parent, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+
+	router := gin.New()
+	router.Use(tracing.Middleware())
+	router.GET("/things/:id", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	request(router, "/things/42", traceID, parent)
+
+	span, ok := spansOf(traceID)["GET /things/:id"]
+	if !ok {
+		t.Fatalf("no server span in trace %s", traceID)
+	}
+	if got := span.Parent().SpanID(); got != parent {
+		t.Errorf("parent span = %s, want %s", got, parent)
+	}
+	if !span.Parent().IsRemote() {
+		t.Error("parent span is not marked remote")
+	}
+	if span.SpanKind() != trace.SpanKindServer {
+		t.Errorf("span kind = %s, want server", span.SpanKind())
+	}
+	if got := attr(span, "http.route"); got != "/things/:id" {
+		t.Errorf("http.route = %q, want /things/:id", got)
+	}
+	if got := attr(span, "http.response.status_code"); got != "204" {
+		t.Errorf("http.response.status_code = %q, want 204", got)
+	}
+}
+
+func TestSpansNestFromRequestToSQL(t *testing.T) {
+	// DryRun builds every statement and runs the callbacks, the tracing
+	// plugin's included, without a database to send them to. Loading the
+	// post's tags fails in DryRun, after the query traced here.
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		Logger:               logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(tracing.NewGormPlugin()); err != nil {
+		t.Fatal(err)
+	}
+	repo := repository.TracePostRepository(repository.NewPostgresPostRepository(db))
+	posts := service.TracePostService(service.NewPostService(repo))
+
+	router := gin.New()
+	router.Use(tracing.Middleware(), middleware.ErrorHandler())
+	router.GET("/api/v1/posts/:id", handlers.NewPostHandler(posts).GetPost)
+
+	const postID = "123e4567-e89b-12d3-a456-426614174000"
+	traceID, _ := trace.TraceIDFromHex("0af7651916cd43dd8448eb211c80319c")
+	parent, _ := trace.SpanIDFromHex("b7ad6b7169203331")
+	request(router, "/api/v1/posts/"+postID, traceID, parent)
+
+	spans := spansOf(traceID)
+	chain := []string{"GET /api/v1/posts/:id", "PostService.GetPost", "PostRepository.GetByID", "gorm.query"}
+	for i, name := range chain {
+		span, ok := spans[name]
+		if !ok {
+			t.Fatalf("no %s span in trace %s", name, traceID)
+		}
+		if i == 0 {
+			continue
+		}
+		if got, want := span.Parent().SpanID(), spans[chain[i-1]].SpanContext().SpanID(); got != want {
+			t.Errorf("%s is a child of %s, want a child of %s", name, got, chain[i-1])
+		}
+	}
+
+	for _, name := range []string{"PostService.GetPost", "PostRepository.GetByID"} {
+		if got := attr(spans[name], "post.id"); got != postID {
+			t.Errorf("%s post.id = %q, want %s", name, got, postID)
+		}
+	}
+
+	query := spans["gorm.query"]
+	if query.SpanKind() != trace.SpanKindClient {
+		t.Errorf("gorm.query span kind = %s, want client", query.SpanKind())
+	}
+	if got := attr(query, "db.query.text"); !strings.Contains(got, `FROM "posts"`) || strings.Contains(got, postID) {
+		t.Errorf("db.query.text = %q, want the posts query with placeholders", got)
+	}
+}
+
+func TestRunRecordsErrors(t *testing.T) {
+	tracer := otel.Tracer("postService/internal/tracing_test")
+	failure := errors.New("boom")
+
+	ctx, root := tracer.Start(context.Background(), "root")
+	traceID := root.SpanContext().TraceID()
+
+	value, err := tracing.Run(ctx, tracer, "run.ok", func(context.Context) (int, error) {
+		return 1, nil
+	}, tracing.PostID("p1"))
+	if value != 1 || err != nil {
+		t.Fatalf("Run = %d, %v; want 1, nil", value, err)
+	}
+	if _, err := tracing.Run(ctx, tracer, "run.failed", func(context.Context) (int, error) {
+		return 0, failure
+	}); err != failure {
+		t.Fatalf("Run error = %v, want %v", err, failure)
+	}
+	if err := tracing.RunErr(ctx, tracer, "runerr.failed", func(context.Context) error {
+		return failure
+	}, tracing.CommentID("c1")); err != failure {
+		t.Fatalf("RunErr error = %v, want %v", err, failure)
+	}
+	root.End()
+
+	spans := spansOf(traceID)
+	if got := spans["run.ok"].Status().Code; got != codes.Unset {
+		t.Errorf("run.ok status = %s, want unset", got)
+	}
+	if got := attr(spans["run.ok"], "post.id"); got != "p1" {
+		t.Errorf("run.ok post.id = %q, want p1", got)
+	}
+	if got := attr(spans["runerr.failed"], "comment.id"); got != "c1" {
+		t.Errorf("runerr.failed comment.id = %q, want c1", got)
+	}
+	for _, name := range []string{"run.failed", "runerr.failed"} {
+		span := spans[name]
+		if span == nil {
+			t.Fatalf("no %s span", name)
+		}
+		if got := span.Status(); got.Code != codes.Error || got.Description != "boom" {
+			t.Errorf("%s status = %+v, want error boom", name, got)
+		}
+		if events := span.Events(); len(events) != 1 || events[0].Name != "exception" {
+			t.Errorf("%s events = %+v, want one exception", name, events)
+		}
+		if got := span.Parent().SpanID(); got != root.SpanContext().SpanID() {
+			t.Errorf("%s parent = %s, want the root span", name, got)
+		}
+	}
+}
+
+func TestSetupWithoutExporterInstallsNoopProvider(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	cfg := config.Default().Tracing
+	cfg.Exporter = "none"
+	shutdown, err := tracing.Setup(context.Background(), cfg, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, span := otel.Tracer("test").Start(context.Background(), "ignored")
+	if span.IsRecording() || span.SpanContext().IsValid() {
+		t.Error("span from the none exporter's provider is recorded")
+	}
+	span.End()
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown: %v", err)
+	}
+}
+
+func TestSetupRejectsUnknownExporter(t *testing.T) {
+	cfg := config.Default().Tracing
+	cfg.Exporter = "zipkin"
+	if _, err := tracing.Setup(context.Background(), cfg, "test"); err == nil {
+		t.Error("unknown exporter was accepted")
+	}
+}
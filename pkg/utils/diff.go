@@ -0,0 +1,230 @@
+package utils
+
+import "strings"
+
+// DiffOp describes how a line changed between two texts
+type DiffOp string
+
+const (
+	DiffEqual  DiffOp = "equal"
+	DiffInsert DiffOp = "insert"
+	DiffDelete DiffOp = "delete"
+)
+
+// DiffLine is a single line of a line-level diff
+type DiffLine struct {
+	Op   DiffOp `json:"op" enums:"equal,insert,delete" example:"insert"`
+	Text string `json:"text" example:"A line that was added"`
+}
+
+// DiffHelper computes line-level differences between texts
+type DiffHelper struct{}
+
+// NewDiffHelper creates a new DiffHelper instance
+func NewDiffHelper() *DiffHelper {
+	return &DiffHelper{}
+}
+
+// Lines returns the line-level diff that turns a into b, based on the longest
+// common subsequence of their lines. Memory stays linear in the number of
+// lines however far apart the texts are.
+func (d *DiffHelper) Lines(a, b string) []DiffLine {
+	from := splitLines(a)
+	to := splitLines(b)
+
+	// Trim the common prefix and suffix so the search only covers the region
+	// that actually changed
+	prefix := 0
+	for prefix < len(from) && prefix < len(to) && from[prefix] == to[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(from)-prefix && suffix < len(to)-prefix &&
+		from[len(from)-1-suffix] == to[len(to)-1-suffix] {
+		suffix++
+	}
+
+	diff := make([]DiffLine, 0, len(from)+len(to))
+	for _, line := range from[:prefix] {
+		diff = append(diff, DiffLine{Op: DiffEqual, Text: line})
+	}
+	diff = append(diff, diffLCS(from[prefix:len(from)-suffix], to[prefix:len(to)-suffix])...)
+	for _, line := range from[len(from)-suffix:] {
+		diff = append(diff, DiffLine{Op: DiffEqual, Text: line})
+	}
+	return diff
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+}
+
+// maxDiffCost bounds the edit distance searched for at each split. Regions
+// further apart than that are reported as replaced wholesale, which keeps the
+// time spent on texts with nothing in common bounded as well.
+const maxDiffCost = 1000
+
+// diffLCS returns a shortest line-level diff that turns from into to, up to
+// maxDiffCost, using Myers' O(ND) algorithm in its linear-space form so memory
+// grows with the number of lines rather than with their product
+func diffLCS(from, to []string) []DiffLine {
+	d := &myersDiff{
+		from:     from,
+		to:       to,
+		deleted:  make([]bool, len(from)),
+		inserted: make([]bool, len(to)),
+		forward:  make([]int, len(from)+len(to)+3),
+		backward: make([]int, len(from)+len(to)+3),
+	}
+	d.compare(0, len(from), 0, len(to))
+
+	// Within a changed run the deletions come before the insertions
+	diff := make([]DiffLine, 0, len(from)+len(to))
+	i, j := 0, 0
+	for i < len(from) || j < len(to) {
+		switch {
+		case i < len(from) && d.deleted[i]:
+			diff = append(diff, DiffLine{Op: DiffDelete, Text: from[i]})
+			i++
+		case j < len(to) && d.inserted[j]:
+			diff = append(diff, DiffLine{Op: DiffInsert, Text: to[j]})
+			j++
+		default:
+			diff = append(diff, DiffLine{Op: DiffEqual, Text: from[i]})
+			i++
+			j++
+		}
+	}
+	return diff
+}
+
+// myersDiff marks which lines of from are deleted and which lines of to are
+// inserted. forward and backward hold the furthest reaching x per diagonal
+// and are reused by every level of the recursion.
+type myersDiff struct {
+	from, to          []string
+	deleted, inserted []bool
+	forward, backward []int
+}
+
+// compare marks the edits that turn from[aLo:aHi] into to[bLo:bHi]
+func (d *myersDiff) compare(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && d.from[aLo] == d.to[bLo] {
+		aLo++
+		bLo++
+	}
+	for aLo < aHi && bLo < bHi && d.from[aHi-1] == d.to[bHi-1] {
+		aHi--
+		bHi--
+	}
+
+	switch {
+	case aLo == aHi:
+		for j := bLo; j < bHi; j++ {
+			d.inserted[j] = true
+		}
+	case bLo == bHi:
+		for i := aLo; i < aHi; i++ {
+			d.deleted[i] = true
+		}
+	default:
+		x, y, ok := d.split(aLo, aHi, bLo, bHi)
+		if !ok {
+			for i := aLo; i < aHi; i++ {
+				d.deleted[i] = true
+			}
+			for j := bLo; j < bHi; j++ {
+				d.inserted[j] = true
+			}
+			return
+		}
+		d.compare(aLo, x, bLo, y)
+		d.compare(x, aHi, y, bHi)
+	}
+}
+
+// split finds a point on a shortest edit path through the region by running
+// the search from both ends until the two paths overlap. The region must
+// start and end with differing lines, so the point is strictly inside it.
+// It gives up once either search has made maxDiffCost edits.
+func (d *myersDiff) split(aLo, aHi, bLo, bHi int) (int, int, bool) {
+	n, m := aHi-aLo, bHi-bLo
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	// Diagonals -maxD to maxD, plus the one above the top the search starts from
+	forward, backward := d.forward[:2*maxD+2], d.backward[:2*maxD+2]
+	for k := range forward {
+		forward[k] = -1
+		backward[k] = -1
+	}
+	forward[offset+1] = 0
+	backward[offset+1] = 0
+
+	delta := n - m
+	// With an odd delta the paths meet while extending forward, otherwise
+	// while extending backward
+	odd := delta%2 != 0
+	// Diagonals that have run off the edge of the region are skipped
+	fStart, fEnd, bStart, bEnd := 0, 0, 0, 0
+
+	for step := 0; step < min(maxD, maxDiffCost); step++ {
+		for k := -step + fStart; k <= step-fEnd; k += 2 {
+			i := offset + k
+			var x int
+			if k == -step || (k != step && forward[i-1] < forward[i+1]) {
+				x = forward[i+1]
+			} else {
+				x = forward[i-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && d.from[aLo+x] == d.to[bLo+y] {
+				x++
+				y++
+			}
+			forward[i] = x
+			switch {
+			case x > n:
+				fEnd += 2
+			case y > m:
+				fStart += 2
+			case odd:
+				if j := offset + delta - k; j >= 0 && j < len(backward) && backward[j] != -1 && x >= n-backward[j] {
+					return aLo + x, bLo + y, true
+				}
+			}
+		}
+
+		for k := -step + bStart; k <= step-bEnd; k += 2 {
+			i := offset + k
+			var x int
+			if k == -step || (k != step && backward[i-1] < backward[i+1]) {
+				x = backward[i+1]
+			} else {
+				x = backward[i-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && d.from[aHi-1-x] == d.to[bHi-1-y] {
+				x++
+				y++
+			}
+			backward[i] = x
+			switch {
+			case x > n:
+				bEnd += 2
+			case y > m:
+				bStart += 2
+			case !odd:
+				if j := offset + delta - k; j >= 0 && j < len(forward) && forward[j] != -1 {
+					fx := forward[j]
+					if fx >= n-x {
+						return aLo + fx, bLo + offset + fx - j, true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []DiffLine
+	}{
+		{"both empty", "", "", []DiffLine{}},
+		{"added text", "", "one\ntwo", []DiffLine{
+			{DiffInsert, "one"}, {DiffInsert, "two"},
+		}},
+		{"removed text", "one\ntwo", "", []DiffLine{
+			{DiffDelete, "one"}, {DiffDelete, "two"},
+		}},
+		{"unchanged", "one\ntwo", "one\r\ntwo", []DiffLine{
+			{DiffEqual, "one"}, {DiffEqual, "two"},
+		}},
+		{"changed line", "one\ntwo\nthree", "one\n2\nthree", []DiffLine{
+			{DiffEqual, "one"}, {DiffDelete, "two"}, {DiffInsert, "2"}, {DiffEqual, "three"},
+		}},
+		{"moved line", "a\nb\nc\nd", "b\nc\na\nd", []DiffLine{
+			{DiffDelete, "a"}, {DiffEqual, "b"}, {DiffEqual, "c"}, {DiffInsert, "a"}, {DiffEqual, "d"},
+		}},
+		{"nothing in common", "a\nb", "c\nd\ne", []DiffLine{
+			{DiffDelete, "a"}, {DiffDelete, "b"}, {DiffInsert, "c"}, {DiffInsert, "d"}, {DiffInsert, "e"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewDiffHelper().Lines(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lines(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDiffLinesIsShortest checks random texts against a quadratic LCS: the
+// diff must rebuild both texts and keep as many lines as possible equal
+func TestDiffLinesIsShortest(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	randomText := func() []string {
+		lines := make([]string, rng.Intn(30))
+		for i := range lines {
+			lines[i] = string(rune('a' + rng.Intn(4)))
+		}
+		return lines
+	}
+
+	for run := 0; run < 2000; run++ {
+		from, to := randomText(), randomText()
+		diff := NewDiffHelper().Lines(strings.Join(from, "\n"), strings.Join(to, "\n"))
+
+		var gotFrom, gotTo []string
+		equal := 0
+		for _, line := range diff {
+			if line.Op != DiffInsert {
+				gotFrom = append(gotFrom, line.Text)
+			}
+			if line.Op != DiffDelete {
+				gotTo = append(gotTo, line.Text)
+			}
+			if line.Op == DiffEqual {
+				equal++
+			}
+		}
+		if strings.Join(gotFrom, "\n") != strings.Join(from, "\n") || strings.Join(gotTo, "\n") != strings.Join(to, "\n") {
+			t.Fatalf("diff of %q and %q does not rebuild them: %v", from, to, diff)
+		}
+		if want := lcsLength(from, to); equal != want {
+			t.Fatalf("diff of %q and %q keeps %d lines, want %d: %v", from, to, equal, want, diff)
+		}
+	}
+}
+
+func lcsLength(a, b []string) int {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	return lcs[0][0]
+}
+
+func TestDiffLinesMemoryIsLinear(t *testing.T) {
+	const lines = 5000
+	from := make([]string, lines)
+	to := make([]string, lines)
+	for i := range from {
+		from[i] = fmt.Sprintf("old %d", i)
+		to[i] = fmt.Sprintf("new %d", i)
+	}
+	a, b := strings.Join(from, "\n"), strings.Join(to, "\n")
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	diff := NewDiffHelper().Lines(a, b)
+	runtime.ReadMemStats(&after)
+
+	if len(diff) != 2*lines {
+		t.Fatalf("diff has %d lines, want %d", len(diff), 2*lines)
+	}
+	// A (lines+1)² table of ints alone would take 200MB
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 8<<20 {
+		t.Errorf("diffing %d lines allocated %d bytes", lines, allocated)
+	}
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// transliterations covers letters that do not decompose into an ASCII base
+// letter plus combining marks
+var transliterations = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'ø': "o", 'œ': "oe", 'ł': "l", 'đ': "d", 'ð': "d",
+	'þ': "th", 'ı': "i", 'ħ': "h", 'ŋ': "ng",
+
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'ґ': "g", 'д': "d", 'е': "e",
+	'ё': "e", 'є': "ye", 'ж': "zh", 'з': "z", 'и': "i", 'і': "i", 'ї': "yi",
+	'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p",
+	'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e",
+	'ю': "yu", 'я': "ya",
+
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i",
+	'θ': "th", 'ι': "i", 'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x",
+	'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t", 'υ': "y",
+	'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+}
+
+// Slugify returns a lowercase, URL-safe form of str made of ASCII letters,
+// digits and single hyphens. Accented and Cyrillic or Greek letters are
+// transliterated; anything else separates words.
+func (s *StringHelper) Slugify(str string) string {
+	var b strings.Builder
+	pendingHyphen := false
+	write := func(part string) {
+		if part == "" {
+			return
+		}
+		if pendingHyphen && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		pendingHyphen = false
+		b.WriteString(part)
+	}
+
+	// NFKD splits accented letters into a base letter and combining marks,
+	// and folds compatibility forms such as ligatures and full-width letters
+	for _, r := range norm.NFKD.String(str) {
+		r = unicode.ToLower(r)
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			continue
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			write(string(r))
+		case transliterations[r] != "":
+			write(transliterations[r])
+		default:
+			if _, silent := transliterations[r]; !silent {
+				pendingHyphen = true
+			}
+		}
+	}
+	return b.String()
+}